@@ -28,6 +28,7 @@
 package blogit
 
 import (
+	"io"
 	"io/fs"
 	"log"
 
@@ -36,8 +37,25 @@ import (
 )
 
 type (
-	Builder    = builder.Builder
-	WritableFS = builder.WritableFS
+	Builder       = builder.Builder
+	WritableFS    = builder.WritableFS
+	ArchiveFS     = builder.ArchiveFS
+	Change        = builder.Change
+	ChangeType    = builder.ChangeType
+	Event         = builder.Event
+	EventType     = builder.EventType
+	BuildResult   = builder.BuildResult
+	Manifest      = builder.Manifest
+	ManifestEntry = builder.ManifestEntry
+	LinkReport    = builder.LinkReport
+	BrokenLink    = builder.BrokenLink
+)
+
+// 文件的变化类型
+const (
+	ChangeCreate = builder.ChangeCreate
+	ChangeModify = builder.ChangeModify
+	ChangeDelete = builder.ChangeDelete
 )
 
 // Version 返回版本号
@@ -69,8 +87,30 @@ func Build(src fs.FS, dest WritableFS, info *log.Logger) error {
 // Deprecated: 请直接使用 &Builder{} 声明变量
 func NewBuilder(src fs.FS, dest WritableFS) *Builder { return builder.New(src, dest) }
 
+// LoadManifest 从 fsys 中加载由 Builder.ManifestFile 指定的清单文件
+func LoadManifest(fsys fs.FS, name string) (*Manifest, error) {
+	return builder.LoadManifest(fsys, name)
+}
+
 // DirFS 以普通目录结构作为保存对象的文件系统
 func DirFS(dir string) WritableFS { return builder.DirFS(dir) }
 
 // MemoryFS 以内在作为保存实体的文件系统
 func MemoryFS() WritableFS { return builder.MemoryFS() }
+
+// ZipFS 以 zip 文件作为保存对象的文件系统
+//
+// 在调用 Close 之后才会将内容写入 w。
+func ZipFS(w io.Writer) ArchiveFS { return builder.ZipFS(w) }
+
+// TarGzFS 以 tar.gz 文件作为保存对象的文件系统
+//
+// 在调用 Close 之后才会将内容写入 w。
+func TarGzFS(w io.Writer) ArchiveFS { return builder.TarGzFS(w) }
+
+// Diff 比较编译结果 curr 相对于已有内容 dest 的变化
+//
+// unified 表示是否为 HTML 和 XML 文件生成 unified 格式的差异内容。
+func Diff(dest, curr fs.FS, unified bool) ([]*Change, error) {
+	return builder.Diff(dest, curr, unified)
+}
@@ -10,13 +10,14 @@ import (
 )
 
 func (b *Builder) buildArchive(d *data.Data) error {
-	p := b.page(vars.ArchiveTemplate)
+	p := b.page(d, vars.ArchiveTemplate)
 	p.Title = d.Archives.Title + d.TitleSuffix
 	p.Permalink = d.Archives.Permalink
 	p.Keywords = d.Archives.Keywords
 	p.Description = d.Archives.Description
 	p.Language = d.Language
 	p.Archives = d.Archives
+	p.Translations = translations(d, func(l *data.Data) (string, string) { return l.Archives.Title, l.Archives.Permalink })
 
-	return b.appendTemplateFile(vars.ArchiveFilename, p)
+	return b.appendTemplateFile(d.Archives.Path, p)
 }
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// 归档文件中所有文件的修改时间
+//
+// 固定的时间可以保证相同的内容生成相同的归档文件，
+// 同时 zip 格式无法表示 1980 年之前的时间。
+var archiveModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// 归档文件中所有文件的权限
+const archiveFileMode = 0o644
+
+// ArchiveFS 以归档文件作为保存对象的文件系统
+//
+// 写入的内容会先保存在内存中，在调用 Close 时按路径排序之后写入归档文件，
+// 所有文件的修改时间和权限都是固定的，所以相同的内容总是生成相同的归档文件。
+type ArchiveFS interface {
+	WritableFS
+
+	// Close 将内容写入归档文件
+	//
+	// 不会关闭归档文件所在的 io.Writer，调用之后不能再写入内容。
+	Close() error
+}
+
+type archiveFS struct {
+	*memoryFS
+	w     io.Writer
+	write func(w io.Writer, src fs.FS, paths []string) error
+
+	closeMux sync.Mutex
+	closed   bool
+}
+
+// ZipFS 返回以 zip 文件作为保存对象的文件系统
+func ZipFS(w io.Writer) ArchiveFS { return newArchiveFS(w, writeZip) }
+
+// TarGzFS 返回以 tar.gz 文件作为保存对象的文件系统
+func TarGzFS(w io.Writer) ArchiveFS { return newArchiveFS(w, writeTarGz) }
+
+func newArchiveFS(w io.Writer, write func(io.Writer, fs.FS, []string) error) *archiveFS {
+	return &archiveFS{
+		memoryFS: newMemoryFS(),
+		w:        w,
+		write:    write,
+	}
+}
+
+// 关闭之后内容已经写入归档文件，不能再修改。
+func (a *archiveFS) isClosed() bool {
+	a.closeMux.Lock()
+	defer a.closeMux.Unlock()
+	return a.closed
+}
+
+func (a *archiveFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if a.isClosed() {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrClosed}
+	}
+	return a.memoryFS.WriteFile(name, data, perm)
+}
+
+func (a *archiveFS) Remove(name string) error {
+	if a.isClosed() {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrClosed}
+	}
+	return a.memoryFS.Remove(name)
+}
+
+func (a *archiveFS) Reset() error {
+	if a.isClosed() {
+		return fs.ErrClosed
+	}
+	return a.memoryFS.Reset()
+}
+
+func (a *archiveFS) Close() error {
+	a.closeMux.Lock()
+	defer a.closeMux.Unlock()
+
+	if a.closed {
+		return fs.ErrClosed
+	}
+	a.closed = true
+
+	paths, err := walkFiles(a.memoryFS)
+	if err != nil {
+		return err
+	}
+
+	return a.write(a.w, a.memoryFS, paths)
+}
+
+func writeZip(w io.Writer, src fs.FS, paths []string) error {
+	zw := zip.NewWriter(w)
+	for _, p := range paths {
+		data, err := fs.ReadFile(src, p)
+		if err != nil {
+			return err
+		}
+
+		h := &zip.FileHeader{Name: p, Method: zip.Deflate, Modified: archiveModTime}
+		h.SetMode(archiveFileMode)
+		f, err := zw.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeTarGz(w io.Writer, src fs.FS, paths []string) error {
+	gw := gzip.NewWriter(w) // 未设置 gzip.Header.ModTime，不会写入时间。
+	tw := tar.NewWriter(gw)
+	for _, p := range paths {
+		data, err := fs.ReadFile(src, p)
+		if err != nil {
+			return err
+		}
+
+		h := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     p,
+			Size:     int64(len(data)),
+			Mode:     archiveFileMode,
+			ModTime:  archiveModTime,
+			Format:   tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+var _ ArchiveFS = &archiveFS{}
+
+func TestArchiveFS(t *testing.T) {
+	a := assert.New(t, false)
+
+	testWritableFS(ZipFS(&bytes.Buffer{}), a)
+	testWritableFS(TarGzFS(&bytes.Buffer{}), a)
+
+	// 写入之后不能再写入
+	buf := &bytes.Buffer{}
+	afs := ZipFS(buf)
+	a.NotError(afs.WriteFile("a.txt", []byte("a"), fs.ModePerm))
+	a.NotError(afs.Close())
+	a.ErrorIs(afs.WriteFile("b.txt", []byte("b"), fs.ModePerm), fs.ErrClosed).
+		ErrorIs(afs.(RemovableFS).Remove("a.txt"), fs.ErrClosed).
+		ErrorIs(afs.Reset(), fs.ErrClosed).
+		ErrorIs(afs.Close(), fs.ErrClosed)
+}
+
+func TestZipFS(t *testing.T) {
+	a := assert.New(t, false)
+
+	build := func() []byte {
+		buf := &bytes.Buffer{}
+		afs := ZipFS(buf)
+		b := &Builder{Src: testdata.MapFS(t), Dest: afs, Concurrency: 4}
+		a.NotError(b.Rebuild())
+		a.NotError(b.Rebuild()) // 多次编译不会产生重复的文件
+		a.NotError(afs.Close())
+		return buf.Bytes()
+	}
+
+	data := build()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	a.NotError(err)
+
+	names := make([]string, 0, len(r.File))
+	for _, f := range r.File {
+		names = append(names, f.Name)
+		a.Equal(f.Modified.UTC(), archiveModTime).
+			Equal(f.Mode().Perm(), fs.FileMode(archiveFileMode))
+	}
+	a.Contains(names, "index"+vars.Ext).
+		Contains(names, "themes/default/style.css").
+		Equal(len(names), len(r.File))
+
+	f, err := r.Open("posts/p1" + vars.Ext)
+	a.NotError(err)
+	content, err := io.ReadAll(f)
+	a.NotError(err).NotEmpty(content)
+}
+
+func TestTarGzFS(t *testing.T) {
+	a := assert.New(t, false)
+
+	buf := &bytes.Buffer{}
+	afs := TarGzFS(buf)
+	a.NotError(afs.WriteFile("b/b.txt", []byte("b"), fs.ModePerm))
+	a.NotError(afs.WriteFile("a.txt", []byte("a"), fs.ModePerm))
+	a.NotError(afs.WriteFile("c.txt", []byte("c"), fs.ModePerm))
+	a.NotError(afs.Close())
+
+	gr, err := gzip.NewReader(buf)
+	a.NotError(err)
+	tr := tar.NewReader(gr)
+
+	names := make([]string, 0, 3)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		a.NotError(err).
+			Equal(h.ModTime.UTC(), archiveModTime).
+			Equal(h.Mode, int64(archiveFileMode))
+		names = append(names, h.Name)
+	}
+	a.Equal(names, []string{"a.txt", "b/b.txt", "c.txt"})
+
+	// 相同的内容生成相同的归档文件
+	build := func() []byte {
+		buf := &bytes.Buffer{}
+		afs := TarGzFS(buf)
+		a.NotError(afs.WriteFile("b.txt", []byte("b"), fs.ModePerm))
+		a.NotError(afs.WriteFile("a.txt", []byte("a"), fs.ModePerm))
+		a.NotError(afs.Close())
+		return buf.Bytes()
+	}
+	a.Equal(build(), build())
+}
@@ -76,5 +76,5 @@ func (b *Builder) buildAtom(d *data.Data) error {
 		})
 	}
 
-	return b.appendXMLFile(d.Atom.Path, d.Atom.XSLPermalink, a)
+	return b.appendXMLFile(d.Atom.Path, d.Atom.XSLPermalink, PhaseAtom, a)
 }
@@ -6,6 +6,7 @@
 package builder
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"html/template"
@@ -15,6 +16,7 @@ import (
 	"path"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/issue9/errwrap"
@@ -23,12 +25,14 @@ import (
 	"github.com/caixw/blogit/v2/internal/data"
 	"github.com/caixw/blogit/v2/internal/filesystem"
 	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/pool"
 	"github.com/caixw/blogit/v2/internal/vars"
 )
 
 // ErrBuilding 另一个 Rebuild 正在执行
 //
 // 当多次快速调用 Builder.Rebuild 时，可能返回此值， 表示另一个调用还未返回，新的调用又开始。
+// 如果不希望丢失编译请求，可以使用 Builder.RequestRebuild。
 var ErrBuilding = errors.New("正在编译中")
 
 // Builder 提供了一个可重复生成 HTML 内容的对象
@@ -54,14 +58,85 @@ type Builder struct {
 	// 一般在预览模式下，需要将其替换成本地的地址。
 	BaseURL string
 
+	// 是否启用增量编译
+	//
+	// 启用之后会记录文章的解析结果以及输出文件的 hash 值，
+	// 再次编译时，仅重新解析内容有变化的文章，也仅写入内容有变化的文件。
+	// 模板、conf.yaml 和 tags.yaml 等文件的变化，会反映在输出文件的内容上，
+	// 所以依然能正确地更新相关的页面。
+	Incremental bool
+
+	// 增量编译时缓存的保存路径
+	//
+	// 仅在 Incremental 为 true 时有效。为空表示仅缓存在内存中，
+	// 像 build 这种每次都启动新进程的场景，可以指定该值，以便在多次编译之间共享缓存。
+	CacheFile string
+
+	// 同时执行编译任务的最大数量
+	//
+	// 包括 markdown 的解析和各个页面的渲染，小于等于 1 表示按顺序执行。
+	// 不管该值为多少，输出的内容都是相同的。
+	Concurrency int
+
+	// 编译过程中的事件通知
+	//
+	// 可以为空，该函数不会被并发调用，但是可能在不同的 goroutine 中调用。
+	OnEvent func(*Event)
+
+	// 清单文件的路径
+	//
+	// 如果不为空，会在 Dest 中生成该文件，记录了所有输出文件的大小、hash 以及来源等信息，
+	// 比如 .blogit-manifest.json。路径相对于 Dest，且不能以 / 开头。
+	ManifestFile string
+
+	// 是否检测站内链接
+	//
+	// 如果为 true，会在提交之前检测所有 HTML 页面中的站内链接，
+	// 结果保存在 BuildResult.Links 中，即使存在无效的链接也不会中断编译。
+	CheckLinks bool
+
+	// 是否在定时发布的文章到达发布时间或是文章过期时自动重新编译
+	//
+	// 创建时间晚于当前时间的文章不会被编译，设置了过期时间的文章在过期之后也不会再被编译，
+	// 如果为 true，在每次编译成功之后，会在 BuildResult.Scheduled 指定的时间调用 RequestRebuild。
+	// 预览模式下会编译所有的文章，该值不启作用。
+	AutoPublish bool
+
 	rebuildMux sync.Mutex // 防止多次调用 Rebuild
 	building   bool
-	builded    time.Time // 最后一次编译时间
+	builded    time.Time                   // 最后一次编译时间
+	result     atomic.Pointer[BuildResult] // 最后一次编译的统计报告，Result 可能在编译期间被调用。
+
+	// RequestRebuild 的相关状态
+	requestMux sync.Mutex
+	requesting bool         // 是否有处理请求的 goroutine 正在运行
+	requests   []chan error // 等待下一次编译的请求
+
+	// AutoPublish 的相关状态
+	scheduleMux   sync.Mutex
+	scheduleTimer *time.Timer
+
+	// 文章旧地址的跳转规则，以跳转页面的路径为键名，目标地址为键值。
+	redirectsMux sync.RWMutex
+	redirects    map[string]string
 
 	// 以下内容在 Rebuild 之后会重新生成
 
-	site *site
-	tpl  *template.Template
+	sites        map[string]*site // 以语言 ID 为键名
+	tpl          *template.Template
+	cache        *cache
+	minify       *loader.Minify
+	fingerprints *fingerprints
+
+	// 以下内容仅在 Rebuild 期间有效
+
+	ctx        context.Context
+	stage      *memoryFS         // 暂存区
+	sources    map[string]source // 暂存区中各个文件的来源
+	sourcesMux sync.Mutex
+	current    *BuildResult // 当前编译的统计报告
+
+	stageRedirects map[string]string // 提交之后替换 redirects
 }
 
 // New 声明 Builder 实例
@@ -76,8 +151,22 @@ func New(src fs.FS, dest WritableFS) *Builder {
 
 // Rebuild 重新生成数据
 //
+// 所有的内容会先写入一个暂存区，只有在全部生成成功之后，才会提交到 Dest。
+// 所以在编译期间以及编译失败之后，Dest 依然保持着上一次编译成功时的内容。
+// 如果 Dest 是由 MemoryFS 创建的，提交是原子性的，即通过 Handler
+// 访问时，只会看到完整的旧内容或是完整的新内容；
+// 由 DirFS 创建的，则会以单个文件为单位进行替换，中途写入失败时，
+// 已经写入的文件会被恢复为上一次编译成功时的内容。
+//
 // 返回的 error 可能实现了 localeutil.LocaleStringer 接口。
-func (b *Builder) Rebuild() error {
+func (b *Builder) Rebuild() error { return b.RebuildContext(context.Background()) }
+
+// RebuildContext 重新生成数据
+//
+// 与 Rebuild 相同，但是可以通过 ctx 取消编译。
+// 取消只在提交到 Dest 之前有效，一旦开始提交，会等待提交完成，
+// 以保证 Dest 中的内容是完整的。
+func (b *Builder) RebuildContext(ctx context.Context) (err error) {
 	b.rebuildMux.Lock()
 	defer b.rebuildMux.Unlock()
 
@@ -88,73 +177,352 @@ func (b *Builder) Rebuild() error {
 	defer func() { b.building = false }()
 	b.building = true
 
-	if err := b.Dest.Reset(); err != nil {
+	b.current = newResult()
+	b.emit(&Event{Type: EventStart})
+	defer func() {
+		b.current.end()
+		r := b.current
+		b.result.Store(r)
+		b.current = nil
+		b.emit(&Event{Type: EventEnd, Duration: r.Duration, Err: err})
+	}()
+
+	if b.cache == nil {
+		var file string
+		if b.Incremental {
+			file = b.CacheFile
+		}
+
+		c, err := loadCache(file)
+		if err != nil {
+			return err
+		}
+		b.cache = c
+	}
+
+	b.ctx = ctx
+	b.stage = newMemoryFS()
+	b.sources = make(map[string]source, 100)
+	defer func() {
+		b.ctx = nil
+		b.stage = nil
+		b.sources = nil
+		b.stageRedirects = nil
+	}()
+
+	if err := b.rebuild(); err != nil {
 		return err
 	}
 
-	paths := make([]string, 0, 100)
-	err := fs.WalkDir(b.Src, ".", func(path string, d fs.DirEntry, err error) error {
-		if err == nil && !d.IsDir() && !isIgnore(path) {
-			paths = append(paths, path)
-		}
+	if err := b.phase(ctx, PhaseCommit, b.commit); err != nil {
 		return err
+	}
+
+	b.redirectsMux.Lock()
+	b.redirects = b.stageRedirects
+	b.redirectsMux.Unlock()
+
+	if b.Incremental {
+		if err := b.cache.save(b.CacheFile); err != nil {
+			return err
+		}
+	}
+
+	b.builded = time.Now()
+	if b.AutoPublish {
+		b.schedule(b.current.Scheduled)
+	}
+	return nil
+}
+
+// 在 t 时刻请求重新编译，之前的计划会被取消，t 为零值表示仅取消之前的计划。
+func (b *Builder) schedule(t time.Time) {
+	b.scheduleMux.Lock()
+	defer b.scheduleMux.Unlock()
+
+	if b.scheduleTimer != nil {
+		b.scheduleTimer.Stop()
+		b.scheduleTimer = nil
+	}
+
+	if !t.IsZero() {
+		b.scheduleTimer = time.AfterFunc(time.Until(t), func() { b.RequestRebuild() })
+	}
+}
+
+func (b *Builder) rebuild() error {
+	err := b.phase(b.ctx, PhaseStatic, func() error {
+		paths := make([]string, 0, 100)
+		err := fs.WalkDir(b.Src, ".", func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && !isIgnore(path) {
+				paths = append(paths, path)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		b.current.Statics = len(paths)
+
+		return pool.Run(b.ctx, b.Concurrency, len(paths), func(i int) error {
+			bs, err := fs.ReadFile(b.Src, paths[i])
+			if err != nil {
+				return err
+			}
+			p := loader.Slug(paths[i])
+			return b.appendFile(p, source{typ: SourceStatic, name: p}, bs)
+		})
 	})
 	if err != nil {
 		return err
 	}
 
+	return b.buildData()
+}
+
+// 将暂存区的内容提交到 Dest
+func (b *Builder) commit() error {
+	paths, err := walkFiles(b.stage)
+	if err != nil {
+		return err
+	}
+
+	if b.ManifestFile != "" {
+		if paths, err = b.appendManifest(paths); err != nil {
+			return err
+		}
+	}
+
+	b.cache.begin()
+
+	if m, ok := b.Dest.(*memoryFS); ok {
+		for _, p := range paths {
+			data, err := fs.ReadFile(b.stage, p)
+			if err != nil {
+				return err
+			}
+			if b.cache.changed(m, p, data) {
+				b.written(p, data)
+			}
+		}
+		m.swap(b.stage)
+		for _, p := range b.cache.end() {
+			b.removed(p)
+		}
+		return nil
+	}
+
+	// 无法删除过期的文件，只能在写入之前清空。
+	pr, prunable := b.Dest.(PrunableFS)
+	rm, removable := b.Dest.(RemovableFS)
+	if !prunable && !removable && !b.Incremental {
+		if err := b.Dest.Reset(); err != nil {
+			return err
+		}
+	}
+
+	// 覆盖之前先备份 Dest 中的内容，写入失败时据此恢复已经写入的文件。
+	written := make([]string, 0, len(paths))
+	backup := make(map[string][]byte, len(paths))
 	for _, p := range paths {
-		bs, err := fs.ReadFile(b.Src, p)
+		data, err := fs.ReadFile(b.stage, p)
+		if err == nil && (b.cache.changed(b.Dest, p, data) || !b.Incremental) {
+			if err = backupFile(b.Dest, p, backup); err == nil {
+				written = append(written, p)
+				if err = b.Dest.WriteFile(p, data, fs.ModePerm); err == nil {
+					b.written(p, data)
+				}
+			}
+		}
 		if err != nil {
+			b.cache.abort(b.rollback(written, backup)...)
 			return err
 		}
-		if err = b.appendFile(loader.Slug(p), bs); err != nil {
+	}
+
+	stale := b.cache.end()
+
+	if prunable {
+		removed, err := pr.Prune(paths)
+		for _, p := range removed {
+			b.removed(p)
+		}
+		return err
+	}
+
+	for _, p := range stale {
+		if !removable {
+			break
+		}
+		if err := rm.Remove(p); err != nil {
 			return err
 		}
+		b.removed(p)
 	}
 
-	if err := b.buildData(); err != nil {
+	return nil
+}
+
+// 将 dest 中 p 的内容保存至 backup，文件不存在时不作任何处理。
+func backupFile(dest fs.FS, p string, backup map[string][]byte) error {
+	data, err := fs.ReadFile(dest, p)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	case err != nil:
 		return err
 	}
-
-	b.builded = time.Now()
+	backup[p] = data
 	return nil
 }
 
+// 将 written 中的文件恢复为 backup 中的内容
+//
+// 不在 backup 中的文件是本次提交新建的，需要删除。
+// 返回无法恢复的文件。
+func (b *Builder) rollback(written []string, backup map[string][]byte) []string {
+	rm, removable := b.Dest.(RemovableFS)
+
+	dirty := make([]string, 0, len(written))
+	for _, p := range written {
+		var err error
+		if data, found := backup[p]; found {
+			err = b.Dest.WriteFile(p, data, fs.ModePerm)
+		} else if removable {
+			err = rm.Remove(p)
+		} else {
+			err = errors.ErrUnsupported
+		}
+
+		if err != nil {
+			dirty = append(dirty, p)
+		}
+	}
+	return dirty
+}
+
+// RequestRebuild 请求重新编译
+//
+// 与 Rebuild 不同，该方法不会阻塞，编译在另一个 goroutine 中进行。
+// 如果请求时正有编译在进行，则会在其完成之后再执行一次编译，
+// 期间的所有请求都会被合并到这一次编译中，所以不会丢失任何一次请求，
+// 也不会因为请求太多而重复编译。
+//
+// 返回的 channel 会在包含此请求的编译完成之后，接收到 Rebuild 的返回值，之后被关闭。
+// 如果不关心编译结果，可以直接忽略返回值。
+func (b *Builder) RequestRebuild() <-chan error {
+	ch := make(chan error, 1)
+
+	b.requestMux.Lock()
+	defer b.requestMux.Unlock()
+
+	b.requests = append(b.requests, ch)
+	if !b.requesting {
+		b.requesting = true
+		go b.serveRequests()
+	}
+
+	return ch
+}
+
+func (b *Builder) serveRequests() {
+	for {
+		b.requestMux.Lock()
+		requests := b.requests
+		b.requests = nil
+		if len(requests) == 0 {
+			b.requesting = false
+			b.requestMux.Unlock()
+			return
+		}
+		b.requestMux.Unlock()
+
+		err := b.Rebuild()
+		for _, ch := range requests {
+			ch <- err
+			close(ch)
+		}
+	}
+}
+
 // Builded 最后的编译时间
 func (b *Builder) Builded() time.Time {
 	return b.builded
 }
 
 func (b *Builder) buildData() (err error) {
-	d, err := data.Load(b.Src, b.Preview, b.BaseURL)
+	o := &data.Options{
+		Preview:     b.Preview,
+		BaseURL:     b.BaseURL,
+		Concurrency: b.Concurrency,
+		Phase:       func(name string, f func() error) error { return b.phase(b.ctx, name, f) },
+	}
+	if b.Incremental {
+		o.Cache = b.cache.Posts
+	}
+
+	var d *data.Data
+	err = b.phase(b.ctx, PhaseLoad, func() (err error) {
+		d, err = data.Load(b.ctx, b.Src, o)
+		return err
+	})
 	if err != nil {
 		return err
 	}
+	for _, l := range d.AllLanguages() {
+		b.current.Posts += len(l.Posts) + len(l.Unlisted)
+		b.current.Tags += len(l.Tags.Tags)
+		b.current.Indexes += len(l.Indexes)
+	}
+	b.current.Scheduled = d.Scheduled
 
-	b.tpl, err = newTemplate(d, b.Src)
+	err = b.phase(b.ctx, PhaseTemplate, func() (err error) {
+		b.fingerprints = newFingerprints()
+		b.tpl, err = newTemplate(d, b.Src, b.fingerprints)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	b.site = newSite(d)
+	b.sites = newSites(d)
+	b.minify = d.Minify
 
-	call := func(f func(*data.Data) error) {
+	call := func(phase string, f func(*data.Data) error) {
 		if err == nil {
-			err = f(d)
+			err = b.phase(b.ctx, phase, func() error { return f(d) })
 		}
 	}
 
-	call(b.buildTags)
-	call(b.buildPosts)
-	call(b.buildIndexes)
-	call(b.buildSitemap)
-	call(b.buildArchive)
-	call(b.buildAtom)
-	call(b.buildRSS)
-	call(b.buildRobots)
-	call(b.buildProfile)
-	call(b.buildHighlights)
+	// 每一种语言都需要单独生成的内容
+	each := func(phase string, f func(*data.Data) error) {
+		call(phase, func(d *data.Data) error {
+			for _, l := range d.AllLanguages() {
+				if err := f(l); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	call(PhaseHighlights, b.buildHighlights)
+	call(PhaseFingerprint, b.buildFingerprint)
+	each(PhaseTags, b.buildTags)
+	call(PhaseSeries, b.buildSeries)
+	each(PhasePosts, b.buildPosts)
+	call(PhasePages, b.buildPages)
+	call(PhaseRedirects, b.buildRedirects)
+	each(PhaseIndexes, b.buildIndexes)
+	call(PhaseSitemap, b.buildSitemap)
+	each(PhaseArchive, b.buildArchive)
+	each(PhaseAtom, b.buildAtom)
+	each(PhaseRSS, b.buildRSS)
+	call(PhaseRobots, b.buildRobots)
+	call(PhaseProfile, b.buildProfile)
+	call(PhaseMinify, b.buildMinify)
+	call(PhaseCompress, b.buildCompress)
+	call(PhaseCheck, b.buildCheck)
 
 	return
 }
@@ -185,8 +553,15 @@ func isIgnore(src string) bool {
 
 // path 表示输出的文件路径，相对于源目录；
 // xsl 表示关联的 xsl，如果不需要则可能为空；
-func (b *Builder) appendXMLFile(path, xsl string, v interface{}) error {
-	bs, err := xml.MarshalIndent(v, "", "\t")
+// phase 表示生成该文件的阶段；
+func (b *Builder) appendXMLFile(path, xsl, phase string, v interface{}) error {
+	var bs []byte
+	var err error
+	if b.minify != nil && b.minify.XML {
+		bs, err = xml.Marshal(v)
+	} else {
+		bs, err = xml.MarshalIndent(v, "", "\t")
+	}
 	if err != nil {
 		return err
 	}
@@ -202,18 +577,46 @@ func (b *Builder) appendXMLFile(path, xsl string, v interface{}) error {
 		return buf.Err
 	}
 
-	return b.appendFile(path, buf.Bytes())
+	return b.appendFile(path, source{typ: SourceGenerated, name: phase}, buf.Bytes())
 }
 
-// 如果 path 以 / 开头，则会自动去除 /
-func (b *Builder) appendFile(p string, data []byte) error {
+// 将内容写入暂存区
+//
+// 如果 path 以 / 开头，则会自动去除 /；
+// src 表示文件的来源，会记录在清单文件中；
+func (b *Builder) appendFile(p string, src source, data []byte) error {
+	b.sourcesMux.Lock()
+	b.sources[strings.TrimPrefix(p, "/")] = src
+	b.sourcesMux.Unlock()
+
+	return b.stage.WriteFile(p, data, fs.ModePerm)
+}
+
+// 文件 p 已经从 Dest 中删除
+func (b *Builder) removed(p string) {
+	b.current.Removed++
+	b.emit(&Event{Type: EventRemove, Phase: PhaseCommit, Path: p})
+}
+
+// 文件 p 已经写入 Dest
+func (b *Builder) written(p string, data []byte) {
 	if b.Info != nil {
 		b.Info.Println(" >>", p)
 	}
-	return b.Dest.WriteFile(p, data, fs.ModePerm)
+	b.current.Files++
+	b.current.Bytes += int64(len(data))
+	b.emit(&Event{Type: EventWrite, Phase: PhaseCommit, Path: p, Size: len(data)})
 }
 
 // Handler 将当前对象转换成 http.Handler 接口对象
+//
+// 文章的旧地址会以 301 跳转到新地址。
 func (b *Builder) Handler(erro *log.Logger) http.Handler {
-	return filesystem.FileServer(b.Dest, erro)
+	return filesystem.FileServer(b.Dest, b.redirect, erro)
+}
+
+func (b *Builder) redirect(p string) string {
+	b.redirectsMux.RLock()
+	defer b.redirectsMux.RUnlock()
+	return b.redirects[p]
 }
@@ -5,13 +5,24 @@
 package builder
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
 	"log"
 	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/issue9/assert/v4"
 	"github.com/issue9/assert/v4/rest"
 
+	"github.com/caixw/blogit/v2/internal/filesystem"
 	"github.com/caixw/blogit/v2/internal/testdata"
 	"github.com/caixw/blogit/v2/internal/vars"
 )
@@ -80,3 +91,472 @@ func TestBuilder_Handler(t *testing.T) {
 	srv.Get("/index" + vars.Ext).Do(nil).Status(http.StatusOK)
 	srv.Get("/themes/").Do(nil).Status(http.StatusNotFound) // 目录下没有 index.html
 }
+
+func TestBuilder_Incremental(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	buf := &bytes.Buffer{}
+
+	b := &Builder{
+		Src:         src,
+		Dest:        MemoryFS(),
+		Info:        log.New(buf, "", 0),
+		Incremental: true,
+	}
+	a.NotError(b.Rebuild())
+	a.Contains(buf.String(), "themes/default/style.css").
+		True(filesystem.Exists(b.Dest, "posts/p1"+vars.Ext))
+
+	// 内容未变化的文件不再写入
+	buf.Reset()
+	a.NotError(b.Rebuild())
+	a.NotContains(buf.String(), "themes/default/style.css").
+		True(filesystem.Exists(b.Dest, "themes/default/style.css"))
+
+	// 删除的文章，其输出文件也被删除
+	delete(src, "posts/p1.md")
+	a.NotError(b.Rebuild())
+	a.False(filesystem.Exists(b.Dest, "posts/p1"+vars.Ext)).
+		True(filesystem.Exists(b.Dest, "themes/default/style.css"))
+	a.Equal(4, len(b.cache.Posts.Posts)) // 包含草稿以及独立页面
+
+	// 编译失败，依然保留已有的内容
+	src["conf.yaml"] = &fstest.MapFile{Data: []byte("title: x")}
+	a.Error(b.Rebuild())
+	a.True(filesystem.Exists(b.Dest, "themes/default/style.css"))
+
+	// CacheFile
+
+	destDir, err := testdata.Temp()
+	a.NotError(err)
+	cacheFile := filepath.Join(destDir, "..", filepath.Base(destDir)+".json")
+	src = testdata.MapFS(t)
+
+	b = &Builder{
+		Src:         src,
+		Dest:        DirFS(destDir),
+		Incremental: true,
+		CacheFile:   cacheFile,
+	}
+	a.NotError(b.Rebuild()).
+		FileExists(cacheFile)
+
+	// 新的 Builder 实例，使用同一个缓存文件。
+	buf.Reset()
+	b = &Builder{
+		Src:         src,
+		Dest:        DirFS(destDir),
+		Info:        log.New(buf, "", 0),
+		Incremental: true,
+		CacheFile:   cacheFile,
+	}
+	a.NotError(b.Rebuild())
+	a.NotContains(buf.String(), "themes/default/style.css").
+		Equal(len(b.cache.Posts.Posts), 5)
+}
+
+// 写入指定的文件时失败一次，失败前会写入不完整的内容。
+type failFS struct {
+	WritableFS
+	fail string
+}
+
+func (f *failFS) WriteFile(p string, data []byte, perm fs.FileMode) error {
+	if p == f.fail {
+		f.fail = ""
+		_ = f.WritableFS.WriteFile(p, data[:len(data)/2], perm)
+		return errors.New("write failed")
+	}
+	return f.WritableFS.WriteFile(p, data, perm)
+}
+
+func TestBuilder_Incremental_writeFailed(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	dest := &failFS{WritableFS: DirFS(t.TempDir())}
+	b := &Builder{Src: src, Dest: dest, Incremental: true}
+	a.NotError(b.Rebuild())
+
+	src["posts/p1.md"].Data = append(src["posts/p1.md"].Data, []byte("\nnew content\n")...)
+	dest.fail = "posts/p1" + vars.Ext
+	a.Error(b.Rebuild())
+
+	// 写入失败的文件，在下一次编译时需要重新写入。
+	a.NotError(b.Rebuild())
+	data, err := fs.ReadFile(dest, "posts/p1"+vars.Ext)
+	a.NotError(err).Contains(string(data), "new content").Contains(string(data), "</html>")
+}
+
+// 写入指定的文件时失败，其它操作与 dirFS 相同。
+type failDirFS struct {
+	*dirFS
+	fail string
+}
+
+func (f *failDirFS) WriteFile(p string, data []byte, perm fs.FileMode) error {
+	if p == f.fail {
+		f.fail = ""
+		return errors.New("write failed")
+	}
+	return f.dirFS.WriteFile(p, data, perm)
+}
+
+// 读取 dir 下的所有文件内容
+func readDir(a *assert.Assertion, dir string) map[string]string {
+	files := make(map[string]string, 50)
+	a.NotError(filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		files[p] = string(data)
+		return err
+	}))
+	return files
+}
+
+func TestBuilder_Rebuild_rollback(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	dir := t.TempDir()
+	dest := &failDirFS{dirFS: DirFS(dir).(*dirFS)}
+	b := &Builder{Src: src, Dest: dest, Incremental: true}
+	a.NotError(b.Rebuild())
+	prev := readDir(a, dir)
+
+	// posts/p1.html、posts/p4.html 写入之后，sitemap.xml 写入失败，
+	// Dest 需要恢复为上一次编译成功时的内容。
+	src["posts/p1.md"].Data = append(src["posts/p1.md"].Data, []byte("\nnew content\n")...)
+	src["posts/p4.md"] = &fstest.MapFile{Data: src["posts/p1.md"].Data}
+	dest.fail = "sitemap.xml"
+	a.Error(b.Rebuild())
+	a.Equal(readDir(a, dir), prev)
+
+	a.NotError(b.Rebuild())
+	data, err := fs.ReadFile(dest, "posts/p1"+vars.Ext)
+	a.NotError(err).Contains(string(data), "new content")
+	a.FileExists(filepath.Join(dir, "posts", "p4"+vars.Ext))
+}
+
+func TestBuilder_Rebuild_prune(t *testing.T) {
+	a := assert.New(t, false)
+	dest := t.TempDir()
+	src := testdata.MapFS(t)
+
+	a.NotError((&Builder{Src: src, Dest: DirFS(dest)}).Rebuild())
+	a.FileExists(filepath.Join(dest, "posts", "p1"+vars.Ext))
+
+	// 新的 Builder 实例，也能删除不再生成的文件，且不影响其它文件。
+	a.NotError(os.WriteFile(filepath.Join(dest, "CNAME"), []byte("example.com"), os.ModePerm))
+	delete(src, "posts/2020/p2.md")
+	delete(src, "posts/2020/12/p3.md")
+	delete(src, "posts/2020/img.svg")
+	a.NotError((&Builder{Src: src, Dest: DirFS(dest)}).Rebuild())
+	a.FileExists(filepath.Join(dest, "posts", "p1"+vars.Ext)).
+		FileNotExists(filepath.Join(dest, "posts", "2020")).
+		FileExists(filepath.Join(dest, "CNAME"))
+}
+
+func TestBuilder_Concurrency(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+
+	serial := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(serial.Rebuild())
+
+	parallel := &Builder{Src: src, Dest: DirFS(t.TempDir()), Concurrency: 8}
+	a.NotError(parallel.Rebuild())
+
+	// 除了包含编译时间的页面，其它内容应该完全相同。
+	err := fs.WalkDir(serial.Dest, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) == vars.Ext {
+			return err
+		}
+
+		data1, err := fs.ReadFile(serial.Dest, p)
+		a.NotError(err)
+		data2, err := fs.ReadFile(parallel.Dest, p)
+		a.NotError(err)
+		a.Equal(data1, data2, "%s 的内容不相同", p)
+		return nil
+	})
+	a.NotError(err)
+
+	// 出错时依然能返回错误
+	src["posts/err.md"] = &fstest.MapFile{Data: []byte("---\ntitle: err\n---\n")}
+	parallel = &Builder{Src: src, Dest: MemoryFS(), Concurrency: 8}
+	a.Error(parallel.Rebuild())
+}
+
+func TestBuilder_Rebuild_atomic(t *testing.T) {
+	a := assert.New(t, false)
+
+	for _, dest := range []WritableFS{MemoryFS(), DirFS(t.TempDir())} {
+		src := testdata.MapFS(t)
+		b := &Builder{Src: src, Dest: dest, Concurrency: 4}
+		a.NotError(b.Rebuild())
+		srv := rest.NewServer(a, b.Handler(nil), nil)
+
+		// 编译期间不断访问，不应该出现 404。
+		exit := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				select {
+				case <-exit:
+					return
+				default:
+					srv.Get("/posts/p1" + vars.Ext).Do(nil).Status(http.StatusOK)
+					srv.Get("/themes/default/style.css").Do(nil).Status(http.StatusOK)
+				}
+			}
+		}()
+		for i := 0; i < 5; i++ {
+			a.NotError(b.Rebuild())
+		}
+		close(exit)
+		<-done
+
+		// 编译失败，依然保留上一次的内容。
+		delete(src, "posts/p1.md")
+		src["tags.yaml"] = &fstest.MapFile{Data: []byte("title: tags\norder: xx\n")}
+		a.Error(b.Rebuild())
+		srv.Get("/posts/p1" + vars.Ext).Do(nil).Status(http.StatusOK)
+		srv.Get("/index" + vars.Ext).Do(nil).Status(http.StatusOK)
+
+		// 编译成功，删除不再生成的文件。
+		src["tags.yaml"] = testdata.MapFS(t)["tags.yaml"]
+		a.NotError(b.Rebuild())
+		srv.Get("/posts/p1" + vars.Ext).Do(nil).Status(http.StatusNotFound)
+		srv.Get("/posts/2020/p2" + vars.Ext).Do(nil).Status(http.StatusOK)
+	}
+}
+
+// 记录 conf.yaml 的读取次数，即编译次数。
+type countFS struct {
+	fs.FS
+	count int32
+}
+
+func (f *countFS) Open(name string) (fs.File, error) {
+	if name == vars.ConfYAML {
+		atomic.AddInt32(&f.count, 1)
+	}
+	return f.FS.Open(name)
+}
+
+func TestBuilder_RequestRebuild(t *testing.T) {
+	a := assert.New(t, false)
+	src := &countFS{FS: testdata.MapFS(t)}
+	b := &Builder{Src: src, Dest: MemoryFS()}
+
+	a.NotError(<-b.RequestRebuild())
+	a.Equal(atomic.LoadInt32(&src.count), 1).
+		True(filesystem.Exists(b.Dest, "index"+vars.Ext))
+
+	// 多个请求被合并
+	chs := make([]<-chan error, 0, 20)
+	for i := 0; i < 20; i++ {
+		chs = append(chs, b.RequestRebuild())
+	}
+	for _, ch := range chs {
+		a.NotError(<-ch)
+		_, ok := <-ch
+		a.False(ok) // 已关闭
+	}
+	cnt := atomic.LoadInt32(&src.count)
+	a.True(cnt > 1 && cnt <= 3, "编译次数 %d", cnt)
+
+	// 编译过程中的请求，会在当前编译结束之后再执行一次。
+	atomic.StoreInt32(&src.count, 0)
+	done := make(chan struct{})
+	go func() {
+		a.NotError(b.Rebuild())
+		close(done)
+	}()
+	ch := b.RequestRebuild()
+	<-done
+	a.NotError(<-ch)
+	a.True(atomic.LoadInt32(&src.count) >= 2)
+
+	// 编译出错
+	src.FS.(fstest.MapFS)["conf.yaml"] = &fstest.MapFile{Data: []byte("title: x")}
+	a.Error(<-b.RequestRebuild())
+}
+
+func TestBuilder_RebuildContext(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+
+	events := make([]*Event, 0, 100)
+	b := &Builder{
+		Src:         src,
+		Dest:        MemoryFS(),
+		Concurrency: 4,
+		OnEvent:     func(e *Event) { events = append(events, e) },
+	}
+	a.NotError(b.RebuildContext(context.Background()))
+
+	a.Equal(events[0].Type, EventStart).
+		Equal(events[len(events)-1].Type, EventEnd).
+		NotError(events[len(events)-1].Err).
+		NotZero(events[len(events)-1].Duration)
+
+	phases := make([]string, 0, 20)
+	writes := make(map[string]int, 50)
+	for _, e := range events {
+		a.NotZero(e.Time)
+		switch e.Type {
+		case EventPhaseStart:
+			phases = append(phases, e.Phase)
+		case EventWrite:
+			a.Equal(e.Phase, PhaseCommit)
+			writes[e.Path] = e.Size
+		}
+	}
+	a.Equal(phases, []string{
+		PhaseStatic, PhaseLoad, PhaseLoadConfig, PhaseLoadTags, PhaseLoadPosts, PhaseLoadTheme, PhaseLoadProcess, PhaseTemplate, PhaseHighlights, PhaseFingerprint,
+		PhaseTags, PhaseSeries, PhasePosts, PhasePages, PhaseRedirects, PhaseIndexes, PhaseSitemap, PhaseArchive, PhaseAtom, PhaseRSS, PhaseRobots, PhaseProfile, PhaseMinify, PhaseCompress, PhaseCheck, PhaseCommit,
+	})
+	a.True(writes["index"+vars.Ext] > 0)
+
+	// 删除的文件
+	events = events[:0]
+	delete(src, "posts/p1.md")
+	a.NotError(b.Rebuild())
+	removed := false
+	for _, e := range events {
+		if e.Type == EventRemove && e.Path == "posts/p1"+vars.Ext {
+			removed = true
+		}
+	}
+	a.True(removed)
+
+	// 已经取消的 ctx
+	src = testdata.MapFS(t)
+	dest := DirFS(t.TempDir())
+	b = &Builder{Src: src, Dest: dest}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a.ErrorIs(b.RebuildContext(ctx), context.Canceled).
+		False(filesystem.Exists(dest, "index"+vars.Ext))
+
+	// 在编译过程中取消
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var end *Event
+	b.OnEvent = func(e *Event) {
+		if e.Type == EventPhaseStart && e.Phase == PhasePosts {
+			cancel()
+		}
+		if e.Type == EventEnd {
+			end = e
+		}
+	}
+	a.ErrorIs(b.RebuildContext(ctx), context.Canceled).
+		False(filesystem.Exists(dest, "index"+vars.Ext)).
+		ErrorIs(end.Err, context.Canceled)
+
+	// 可以继续编译
+	a.NotError(b.Rebuild()).
+		True(filesystem.Exists(dest, "index"+vars.Ext))
+}
+
+func TestBuilder_Result(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	b := &Builder{Src: src, Dest: MemoryFS(), Concurrency: 4}
+	a.Nil(b.Result())
+
+	a.NotError(b.Rebuild())
+	r := b.Result()
+	a.NotNil(r).
+		NotZero(r.Duration).
+		Equal(r.Posts, 3).
+		Equal(r.Tags, 2).
+		Equal(r.Indexes, 2).
+		True(r.Statics > 0).
+		True(r.Files > r.Statics).
+		True(r.Bytes > 0).
+		Zero(r.Removed).
+		Equal(r.Phases[len(r.Phases)-1].Name, PhaseCommit).
+		Equal(len(r.Pages), slowestPagesSize).
+		True(r.Pages[0].Duration >= r.Pages[9].Duration).
+		True(r.Templates[0].Duration >= r.Templates[len(r.Templates)-1].Duration)
+
+	var count int
+	for _, t := range r.Templates {
+		count += t.Count
+	}
+	a.Equal(count, 11) // 文章、独立页面、标签、系列、索引以及 tags.html 和 archive.html
+
+	// 编译失败也有报告
+	delete(src, "posts/p1.md")
+	src["conf.yaml"] = &fstest.MapFile{Data: []byte("title: x")}
+	a.Error(b.Rebuild())
+	a.NotEqual(b.Result(), r).
+		Zero(b.Result().Posts)
+
+	// 编译期间读取报告
+	src["conf.yaml"] = testdata.MapFS(t)["conf.yaml"]
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			a.NotError(b.Rebuild())
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			a.NotNil(b.Result())
+			return
+		default:
+			b.Result()
+		}
+	}
+}
+
+func TestBuilder_AutoPublish(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	post := func(created, expires time.Time) *fstest.MapFile {
+		data := "---\ntitle: t\ntags: [api]\ncreated: " + created.Format(time.RFC3339Nano) + "\n"
+		if !expires.IsZero() {
+			data += "expires: " + expires.Format(time.RFC3339Nano) + "\n"
+		}
+		return &fstest.MapFile{Data: []byte(data + "---\n")}
+	}
+
+	future := time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC)
+	src["posts/future.md"] = post(future, time.Time{})
+	src["posts/expired.md"] = post(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	b := &Builder{Src: src, Dest: MemoryFS(), AutoPublish: true}
+	a.NotError(b.Rebuild())
+	a.True(b.Result().Scheduled.Equal(future)).
+		Equal(b.Result().Posts, 3).
+		FileNotExistsFS(b.Dest, "posts/future"+vars.Ext).
+		FileNotExistsFS(b.Dest, "posts/expired"+vars.Ext).
+		NotNil(b.scheduleTimer)
+	b.schedule(time.Time{})
+
+	// 预览模式下显示所有的文章
+	b = &Builder{Src: src, Dest: MemoryFS(), Preview: true, AutoPublish: true}
+	a.NotError(b.Rebuild())
+	a.Zero(b.Result().Scheduled).
+		FileExistsFS(b.Dest, "posts/future"+vars.Ext).
+		FileExistsFS(b.Dest, "posts/expired"+vars.Ext).
+		Nil(b.scheduleTimer)
+
+	// 到达发布时间之后自动编译
+	delete(src, "posts/future.md")
+	src["posts/soon.md"] = post(time.Now().Add(300*time.Millisecond), time.Time{})
+	b = &Builder{Src: src, Dest: MemoryFS(), AutoPublish: true}
+	a.NotError(b.Rebuild())
+	a.FileNotExistsFS(b.Dest, "posts/soon"+vars.Ext)
+	a.Wait(time.Second)
+	a.FileExistsFS(b.Dest, "posts/soon"+vars.Ext).
+		Zero(b.Result().Scheduled)
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/caixw/blogit/v2/internal/filesystem"
+	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// 编译的缓存
+//
+// 记录了最后一次提交到 Builder.Dest 的文件，在增量模式下还会缓存文章的解析结果，
+// 且可以保存到文件，以便在多个进程之间共享。
+type cache struct {
+	Version string            `json:"version"` // 生成缓存的程序版本，版本不同时，文章的解析结果会被丢弃。
+	Posts   *loader.Cache     `json:"posts"`
+	Outputs map[string]string `json:"outputs"` // 上一次编译输出的文件及其内容的 hash
+
+	outputs    map[string]string // 当前编译输出的文件
+	outputsMux sync.Mutex
+}
+
+// 加载缓存内容，如果 path 为空或是文件不存在，返回一个空的缓存对象。
+func loadCache(path string) (*cache, error) {
+	c := &cache{}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if c.Posts == nil || c.Version != vars.FullVersion() {
+		c.Posts = loader.NewCache()
+	}
+	c.Version = vars.FullVersion()
+	if c.Outputs == nil {
+		c.Outputs = make(map[string]string, 100)
+	}
+
+	return c, nil
+}
+
+func (c *cache) save(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// 开始新一轮的提交
+func (c *cache) begin() {
+	c.outputs = make(map[string]string, len(c.Outputs))
+}
+
+// 记录输出的文件并判断其内容与上一次提交相比是否有变化
+//
+// 文件在 dest 中已经不存在，也被当作有变化。
+func (c *cache) changed(dest fs.FS, p string, data []byte) bool {
+	hash := loader.Hash(data)
+
+	c.outputsMux.Lock()
+	c.outputs[p] = hash
+	prev, found := c.Outputs[p]
+	c.outputsMux.Unlock()
+
+	return !found || prev != hash || !filesystem.Exists(dest, p)
+}
+
+// 完成提交
+//
+// 返回上一次提交，但是本次未提交的文件。
+func (c *cache) end() []string {
+	stale := make([]string, 0, 10)
+	for p := range c.Outputs {
+		if _, found := c.outputs[p]; !found {
+			stale = append(stale, p)
+		}
+	}
+	sort.Strings(stale)
+
+	c.Outputs = c.outputs
+	c.outputs = nil
+	return stale
+}
+
+// 提交失败
+//
+// 本次已经写入的文件都已恢复为上一次提交的内容，所以记录保持不变。
+// dirty 为未能恢复的文件，其内容已经不可信，需要从记录中删除，以便下次提交时重新写入。
+func (c *cache) abort(dirty ...string) {
+	for _, p := range dirty {
+		delete(c.Outputs, p)
+	}
+	c.outputs = nil
+}
@@ -0,0 +1,249 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"io/fs"
+	neturl "net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/caixw/blogit/v2/internal/data"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// BrokenLink 无法解析的站内链接
+type BrokenLink struct {
+	Page   string `json:"page"`           // 链接所在的页面，相对于 Dest 的路径。
+	Post   string `json:"post,omitempty"` // 页面对应文章的源文件，非文章页面为空。
+	Line   int    `json:"line,omitempty"` // 链接在源文件中的行号，无法确定时为 0。
+	Target string `json:"target"`         // 链接的原始内容
+}
+
+// LinkReport 链接检测的结果
+type LinkReport struct {
+	Broken   []*BrokenLink `json:"broken,omitempty"`
+	External []string      `json:"external,omitempty"` // 所有的外部链接，仅列出，不会检测其是否有效。
+}
+
+var (
+	htmlCommentExpr = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlRawExpr     = regexp.MustCompile(`(?is)(<(script|style)\b[^>]*>).*?</(script|style)\s*>`)
+	htmlTagExpr     = regexp.MustCompile(`<[a-zA-Z][^>]*>`)
+	htmlAttrExpr    = regexp.MustCompile(`\s([^\s=/>]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+)
+
+// 检测暂存区中所有 HTML 页面的站内链接
+//
+// 站内链接包括相对路径以及以 d.URL 开头的链接，
+// 这些链接必须指向本次编译生成的文件，如果带有 #fragment，还会检测对应页面中是否存在该 id。
+func (b *Builder) buildCheck(d *data.Data) error {
+	if !b.CheckLinks {
+		return nil
+	}
+
+	base, err := neturl.Parse(d.URL)
+	if err != nil {
+		return err
+	}
+	basePath := strings.TrimSuffix(base.Path, "/") + "/"
+
+	paths, err := walkFiles(b.stage)
+	if err != nil {
+		return err
+	}
+
+	pages := make(map[string]*htmlPage, len(paths))
+	for _, p := range paths {
+		if ext := strings.ToLower(path.Ext(p)); ext != vars.Ext && ext != ".htm" {
+			continue
+		}
+		content, err := fs.ReadFile(b.stage, p)
+		if err != nil {
+			return err
+		}
+		pages[p] = parseHTMLPage(content)
+	}
+
+	report := &LinkReport{}
+	external := make(map[string]struct{}, 10)
+	for _, p := range sortedKeys(pages) {
+		var lines *postLines
+		broken := make(map[string]struct{}, 5) // 同一页面中相同的链接只报告一次
+		for _, link := range pages[p].links {
+			target, ext := resolveLink(p, link, base, basePath)
+			switch {
+			case ext:
+				external[link] = struct{}{}
+				continue
+			case target == nil || b.checkLink(pages, p, target):
+				continue
+			}
+
+			if _, found := broken[link]; found {
+				continue
+			}
+			broken[link] = struct{}{}
+
+			if lines == nil {
+				lines = b.postLines(p)
+			}
+			report.Broken = append(report.Broken, &BrokenLink{
+				Page:   p,
+				Post:   lines.file,
+				Line:   lines.find(link),
+				Target: link,
+			})
+		}
+	}
+	report.External = sortedKeys(external)
+
+	b.current.Links = report
+	return nil
+}
+
+// 判断 target 指向的内容是否存在
+//
+// page 为链接所在的页面，target.Path 为相对于暂存区的路径。
+func (b *Builder) checkLink(pages map[string]*htmlPage, page string, target *neturl.URL) bool {
+	p := target.Path
+	if p == "" && target.Fragment != "" { // 当前页面的 #fragment
+		p = page
+	}
+
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += vars.IndexFilename
+	}
+	stat, err := fs.Stat(b.stage, p)
+	if err == nil && stat.IsDir() {
+		p = path.Join(p, vars.IndexFilename)
+		stat, err = fs.Stat(b.stage, p)
+	}
+	if err != nil {
+		return false
+	}
+
+	if target.Fragment == "" {
+		return true
+	}
+	if pp, found := pages[p]; found {
+		_, found = pp.ids[target.Fragment]
+		return found
+	}
+	return true // 非 HTML 页面不检测 fragment
+}
+
+// 将页面 page 中的链接 link 解析为相对于暂存区的地址
+//
+// 返回值 ext 表示是否为外部链接，如果 target 为 nil 且 ext 为 false，表示不需要检测。
+func resolveLink(page, link string, base *neturl.URL, basePath string) (target *neturl.URL, ext bool) {
+	if link == "" || link == "#" {
+		return nil, false
+	}
+
+	u, err := neturl.Parse(link)
+	if err != nil {
+		return &neturl.URL{Path: link}, false // 无法解析的链接也作为无效链接
+	}
+
+	root := strings.TrimSuffix(basePath, "/")
+	underBase := u.Path == root || strings.HasPrefix(u.Path, basePath)
+	switch {
+	case u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https": // mailto: 和 data: 等
+		return nil, false
+	case u.Host != "":
+		if !strings.EqualFold(u.Host, base.Host) || !underBase {
+			return nil, true
+		}
+	case strings.HasPrefix(u.Path, "/"):
+		if !underBase { // 同域名下的其它程序
+			return nil, false
+		}
+	case u.Path != "": // 相对路径，超出根目录的会以 ../ 开头，在检测时作为无效链接处理。
+		u.Path = path.Join(path.Dir(page), u.Path) + trailingSlash(u.Path)
+		return u, false
+	default: // 仅有 fragment 或 query
+		return u, false
+	}
+
+	u.Path = strings.TrimPrefix(strings.TrimPrefix(u.Path, root), "/")
+	return u, false
+}
+
+func trailingSlash(p string) string {
+	if strings.HasSuffix(p, "/") {
+		return "/"
+	}
+	return ""
+}
+
+type htmlPage struct {
+	links []string            // 所有 href 和 src 的值，已经反转义。
+	ids   map[string]struct{} // 所有的 id 以及 a.name 的值
+}
+
+func parseHTMLPage(content []byte) *htmlPage {
+	content = htmlCommentExpr.ReplaceAll(content, nil)
+	content = htmlRawExpr.ReplaceAll(content, []byte("$1"))
+
+	p := &htmlPage{ids: make(map[string]struct{}, 10)}
+	for _, tag := range htmlTagExpr.FindAll(content, -1) {
+		name := tag[1:]
+		if i := bytes.IndexAny(name, " \t\r\n/>"); i >= 0 {
+			name = name[:i]
+		}
+
+		for _, attr := range htmlAttrExpr.FindAllSubmatch(tag, -1) {
+			v := html.UnescapeString(string(attr[2]) + string(attr[3]) + string(attr[4]))
+			switch k := strings.ToLower(string(attr[1])); {
+			case k == "href" || k == "src":
+				p.links = append(p.links, strings.TrimSpace(v))
+			case k == "id" || (k == "name" && strings.EqualFold(string(name), "a")):
+				p.ids[v] = struct{}{}
+			}
+		}
+	}
+
+	return p
+}
+
+// 文章源文件的内容，用于查找链接所在的行号。
+type postLines struct {
+	file  string
+	lines []string
+}
+
+// 获取生成页面 p 的文章源文件，如果 p 不是文章页面，返回的对象 file 为空。
+func (b *Builder) postLines(p string) *postLines {
+	src, found := b.sources[p]
+	if !found || src.typ != SourcePost {
+		return &postLines{}
+	}
+
+	file := src.name + vars.MarkdownExt
+	content, err := fs.ReadFile(b.Src, file)
+	if errors.Is(err, fs.ErrNotExist) { // 页面包
+		file = path.Join(src.name, vars.BundleIndex+vars.MarkdownExt)
+		content, err = fs.ReadFile(b.Src, file)
+	}
+	if err != nil {
+		return &postLines{file: file}
+	}
+	return &postLines{file: file, lines: strings.Split(string(content), "\n")}
+}
+
+// 查找 link 在源文件中的行号，从 1 开始，找不到返回 0。
+func (l *postLines) find(link string) int {
+	for i, line := range l.lines {
+		if strings.Contains(line, link) {
+			return i + 1
+		}
+	}
+	return 0
+}
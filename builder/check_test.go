@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	neturl "net/url"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+)
+
+func TestBuilder_buildCheck(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	src["posts/check.md"] = &fstest.MapFile{Data: []byte(`---
+title: check
+created: 2020-01-02T15:16:17+08:00
+tags:
+  - api
+---
+
+## heading
+
+[ok](./p1.html) [self](#heading)
+[abs](https://example.com/posts/2020/p2.html#h2)
+[frag](/posts/2020/p2.html#not-exists)
+
+[missing](../not-exists.html)
+[ext](https://github.com/caixw) [mail](mailto:a@example.com)
+`)}
+
+	b := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+	a.Nil(b.Result().Links)
+
+	b = &Builder{Src: src, Dest: MemoryFS(), CheckLinks: true}
+	a.NotError(b.Rebuild())
+	links := b.Result().Links
+	a.NotNil(links).
+		Contains(links.External, "https://github.com/caixw").
+		NotContains(links.External, "mailto:a@example.com")
+
+	broken := make([]*BrokenLink, 0, 2)
+	for _, l := range links.Broken {
+		if l.Page == "posts/check.html" && l.Line > 0 {
+			broken = append(broken, l)
+		}
+	}
+	a.Equal(broken, []*BrokenLink{
+		{Page: "posts/check.html", Post: "posts/check.md", Line: 12, Target: "/posts/2020/p2.html#not-exists"},
+		{Page: "posts/check.html", Post: "posts/check.md", Line: 14, Target: "../not-exists.html"},
+	})
+}
+
+func TestResolveLink(t *testing.T) {
+	a := assert.New(t, false)
+
+	base, err := neturl.Parse("https://example.com/blog")
+	a.NotError(err)
+
+	resolve := func(page, link string) (string, bool) {
+		u, ext := resolveLink(page, link, base, "/blog/")
+		if u == nil {
+			return "", ext
+		}
+		if u.Fragment != "" {
+			return u.Path + "#" + u.Fragment, ext
+		}
+		return u.Path, ext
+	}
+
+	p, ext := resolve("posts/p1.html", "p2.html#h2")
+	a.False(ext).Equal(p, "posts/p2.html#h2")
+
+	p, ext = resolve("posts/p1.html", "../tags/")
+	a.False(ext).Equal(p, "tags/")
+
+	p, ext = resolve("posts/p1.html", "/blog/posts/p2.html")
+	a.False(ext).Equal(p, "posts/p2.html")
+
+	p, ext = resolve("posts/p1.html", "https://example.com/blog")
+	a.False(ext).Equal(p, "")
+
+	p, ext = resolve("posts/p1.html", "#h2")
+	a.False(ext).Equal(p, "#h2")
+
+	p, ext = resolve("posts/p1.html", "/other/")
+	a.False(ext).Empty(p)
+
+	p, ext = resolve("posts/p1.html", "https://example.com/other")
+	a.True(ext).Empty(p)
+
+	p, ext = resolve("posts/p1.html", "//cdn.example.com/x.js")
+	a.True(ext).Empty(p)
+
+	p, ext = resolve("posts/p1.html", "mailto:a@example.com")
+	a.False(ext).Empty(p)
+}
+
+func TestParseHTMLPage(t *testing.T) {
+	a := assert.New(t, false)
+
+	p := parseHTMLPage([]byte(`<html><head>
+<link rel="stylesheet" href='/style.css' />
+<script src=/app.js>var s = '<a href="/in-script">';</script>
+</head><body>
+<!-- <a href="/in-comment"> -->
+<h2 id="h2">h2</h2><a name="top" href="/a?x=1&amp;y=2">a</a>
+<img
+	src="/img.png" />
+</body></html>`))
+	a.Equal(p.links, []string{"/style.css", "/app.js", "/a?x=1&y=2", "/img.png"}).
+		Equal(p.ids, map[string]struct{}{"h2": {}, "top": {}})
+}
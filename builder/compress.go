@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/caixw/blogit/v2/internal/data"
+	"github.com/caixw/blogit/v2/internal/pool"
+)
+
+// 为暂存区中符合条件的文件生成预压缩的 .gz 文件
+//
+// 必须在所有内容都已经写入暂存区并完成压缩之后执行。
+// 未设置 gzip.Header.ModTime，相同的内容总是生成相同的压缩文件，不会影响增量编译。
+func (b *Builder) buildCompress(d *data.Data) error {
+	if d.Compress == nil {
+		return nil
+	}
+
+	paths, err := walkFiles(b.stage)
+	if err != nil {
+		return err
+	}
+
+	return pool.Run(b.ctx, b.Concurrency, len(paths), func(i int) error {
+		p := paths[i]
+		if !slices.Contains(d.Compress.Types, strings.ToLower(path.Ext(p))) {
+			return nil
+		}
+
+		data, err := fs.ReadFile(b.stage, p)
+		if err != nil {
+			return err
+		}
+		if len(data) < d.Compress.Size {
+			return nil
+		}
+
+		buf := &bytes.Buffer{}
+		w, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+		if err = w.Close(); err != nil {
+			return err
+		}
+
+		return b.appendFile(p+".gz", source{typ: SourceGenerated, name: PhaseCompress}, buf.Bytes())
+	})
+}
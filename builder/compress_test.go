@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestBuilder_buildCompress(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	conf := src[vars.ConfYAML].Data
+	src[vars.ConfYAML] = &fstest.MapFile{Data: append(conf, []byte("\ncompress:\n  size: 500\n  types: [html, xml]\n")...)}
+
+	b := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+
+	html, err := fs.ReadFile(b.Dest, "index"+vars.Ext)
+	a.NotError(err).True(len(html) >= 500)
+	gz, err := fs.ReadFile(b.Dest, "index"+vars.Ext+".gz")
+	a.NotError(err).True(len(gz) < len(html))
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	a.NotError(err)
+	data, err := io.ReadAll(r)
+	a.NotError(err).Equal(data, html)
+
+	a.FileExistsFS(b.Dest, vars.RssXML+".gz").
+		FileNotExistsFS(b.Dest, "robots.txt.gz"). // 不在 types 中
+		FileNotExistsFS(b.Dest, "themes/default/style.css.gz")
+
+	// 相同的内容生成相同的压缩文件
+	a.NotError(b.Rebuild())
+	gz2, err := fs.ReadFile(b.Dest, "index"+vars.Ext+".gz")
+	a.NotError(err).Equal(gz2, gz)
+
+	// Handler
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/index"+vars.Ext, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	b.Handler(nil).ServeHTTP(w, req)
+	a.Equal(w.Code, http.StatusOK).
+		Equal(w.Header().Get("Content-Encoding"), "gzip").
+		Equal(w.Body.Bytes(), gz)
+
+	// 关闭
+	src[vars.ConfYAML] = &fstest.MapFile{Data: conf}
+	a.NotError(b.Rebuild())
+	a.FileNotExistsFS(b.Dest, "index"+vars.Ext+".gz")
+}
@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/issue9/sliceutil"
+
+	"github.com/caixw/blogit/v2/internal/diff"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// ChangeType 文件的变化类型
+type ChangeType int8
+
+// 文件的变化类型
+const (
+	ChangeCreate ChangeType = iota + 1 // 新建的文件
+	ChangeModify                       // 内容有变化的文件
+	ChangeDelete                       // 被删除的文件
+)
+
+// 会生成 unified 格式差异内容的文件扩展名
+var unifiedExts = []string{vars.Ext, ".htm", ".xml", ".xsl"}
+
+// Change 编译结果相对于已有内容的变化
+type Change struct {
+	Type ChangeType
+	Path string
+
+	// unified 格式的差异内容
+	//
+	// 仅在调用 Diff 时指定了 unified 参数，且文件为 HTML 或是 XML 时才有值。
+	Diff string
+}
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeCreate:
+		return "create"
+	case ChangeModify:
+		return "modify"
+	case ChangeDelete:
+		return "delete"
+	default:
+		return "<unknown>"
+	}
+}
+
+// Diff 比较 curr 相对于 dest 的变化
+//
+// dest 为已有的输出内容，curr 为新的编译结果，比如将 Builder.Dest 指定为 MemoryFS 编译之后的内容。
+// 如果 dest 是由 DirFS 创建的，被删除的文件仅包含其记录中由 WriteFile 写入的文件，
+// 与真实编译时会删除的文件是相同的；其它类型则 dest 中的所有文件都参与比较。
+// unified 表示是否为 HTML 和 XML 文件生成 unified 格式的差异内容。
+//
+// 返回的内容按文件路径排序。
+func Diff(dest, curr fs.FS, unified bool) ([]*Change, error) {
+	currFiles, err := walkFiles(curr)
+	if err != nil {
+		return nil, err
+	}
+
+	var destFiles []string
+	if dir, ok := dest.(*dirFS); ok {
+		if err := dir.load(); err != nil {
+			return nil, err
+		}
+		dir.filesMux.Lock()
+		destFiles = sortedKeys(dir.files)
+		dir.filesMux.Unlock()
+	} else if destFiles, err = walkFiles(dest); err != nil {
+		return nil, err
+	}
+
+	changes := make([]*Change, 0, 10)
+	for _, p := range currFiles {
+		data, err := fs.ReadFile(curr, p)
+		if err != nil {
+			return nil, err
+		}
+
+		old, err := fs.ReadFile(dest, p)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			changes = append(changes, newChange(ChangeCreate, p, nil, data, unified))
+		case err != nil:
+			return nil, err
+		case !bytes.Equal(old, data):
+			changes = append(changes, newChange(ChangeModify, p, old, data, unified))
+		}
+	}
+
+	exists := make(map[string]struct{}, len(currFiles))
+	for _, p := range currFiles {
+		exists[p] = struct{}{}
+	}
+	for _, p := range destFiles {
+		if _, found := exists[p]; found {
+			continue
+		}
+
+		old, err := fs.ReadFile(dest, p)
+		if errors.Is(err, fs.ErrNotExist) { // 已经被手动删除
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		changes = append(changes, newChange(ChangeDelete, p, old, nil, unified))
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func newChange(t ChangeType, p string, old, curr []byte, unified bool) *Change {
+	c := &Change{Type: t, Path: p}
+
+	ext := strings.ToLower(path.Ext(p))
+	if unified && sliceutil.Exists(unifiedExts, func(e string, _ int) bool { return e == ext }) {
+		oldName, currName := "a/"+p, "b/"+p
+		switch t {
+		case ChangeCreate:
+			oldName = "/dev/null"
+		case ChangeDelete:
+			currName = "/dev/null"
+		}
+		c.Diff = diff.Unified(oldName, currName, old, curr, 3)
+	}
+
+	return c
+}
+
+// 返回 fsys 中的所有文件，按路径排序。
+func walkFiles(fsys fs.FS) ([]string, error) {
+	paths := make([]string, 0, 100)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			paths = append(paths, p)
+		}
+		return err
+	})
+	return paths, err
+}
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestDiff(t *testing.T) {
+	a := assert.New(t, false)
+	dir := t.TempDir()
+	src := testdata.MapFS(t)
+
+	a.NotError((&Builder{Src: src, Dest: DirFS(dir)}).Rebuild())
+	a.NotError(os.WriteFile(filepath.Join(dir, "CNAME"), []byte("example.com"), os.ModePerm))
+
+	// 相同的内容
+	changes, err := Diff(DirFS(dir), DirFS(dir), true)
+	a.NotError(err).Empty(changes)
+
+	delete(src, "posts/p1.md")
+	src["themes/default/new.css"] = &fstest.MapFile{Data: []byte("body{}")}
+	src["themes/default/style.css"] = &fstest.MapFile{Data: []byte("body{color:red}")}
+	b := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+
+	changes, err = Diff(DirFS(dir), b.Dest, true)
+	a.NotError(err).NotEmpty(changes)
+
+	find := func(p string) *Change {
+		for _, c := range changes {
+			if c.Path == p {
+				return c
+			}
+		}
+		return nil
+	}
+	a.Equal(find("themes/default/new.css").Type, ChangeCreate).
+		Empty(find("themes/default/new.css").Diff). // 非 HTML 和 XML
+		Equal(find("themes/default/style.css").Type, ChangeModify).
+		Equal(find("posts/p1"+vars.Ext).Type, ChangeDelete).
+		Contains(find("posts/p1"+vars.Ext).Diff, "+++ /dev/null").
+		Equal(find("index"+vars.Ext).Type, ChangeModify).
+		Contains(find("index"+vars.Ext).Diff, "--- a/index"+vars.Ext).
+		Nil(find("CNAME")) // 非编译生成的文件
+
+	// Dest 不存在
+	changes, err = Diff(DirFS(filepath.Join(dir, "not-exists")), b.Dest, false)
+	a.NotError(err).NotEmpty(changes)
+	for _, c := range changes {
+		a.Equal(c.Type, ChangeCreate).Empty(c.Diff)
+	}
+
+	a.Equal(ChangeCreate.String(), "create").
+		Equal(ChangeType(100).String(), "<unknown>")
+}
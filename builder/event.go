@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"context"
+	"time"
+
+	"github.com/caixw/blogit/v2/internal/data"
+)
+
+// EventType 事件的类型
+type EventType int8
+
+// 事件的类型
+const (
+	EventStart      EventType = iota // 开始编译
+	EventPhaseStart                  // 某一阶段开始
+	EventPhaseEnd                    // 某一阶段结束
+	EventWrite                       // 向 Builder.Dest 写入文件
+	EventRemove                      // 从 Builder.Dest 删除文件
+	EventEnd                         // 编译结束
+)
+
+// 编译的各个阶段
+//
+// 按执行的先后顺序排列，其中 PhaseCommit 之前的阶段都只是在暂存区中进行，
+// 不会对 Builder.Dest 产生任何影响。
+const (
+	PhaseStatic      = "static" // 复制静态文件
+	PhaseLoad        = "load"   // 加载并处理数据，包含了以下以 PhaseLoad 开头的子阶段。
+	PhaseLoadConfig  = data.PhaseConfig
+	PhaseLoadTags    = data.PhaseTags
+	PhaseLoadPosts   = data.PhasePosts
+	PhaseLoadTheme   = data.PhaseTheme
+	PhaseLoadProcess = data.PhaseProcess
+	PhaseTemplate    = "template"    // 加载主题模板
+	PhaseHighlights  = "highlights"  // 生成代码高亮的 CSS 文件
+	PhaseFingerprint = "fingerprint" // 生成带内容哈希的主题资源文件
+	PhaseTags        = "tags"        // 生成标签页
+	PhaseSeries      = "series"      // 生成系列的概览页
+	PhasePosts       = "posts"       // 生成文章页
+	PhasePages       = "pages"       // 生成独立页面
+	PhaseRedirects   = "redirects"   // 生成文章旧地址的跳转页面以及重定向规则
+	PhaseIndexes     = "indexes"     // 生成索引页
+	PhaseSitemap     = "sitemap"     // 生成 sitemap.xml
+	PhaseArchive     = "archive"     // 生成存档页
+	PhaseAtom        = "atom"        // 生成 atom.xml
+	PhaseRSS         = "rss"         // 生成 rss.xml
+	PhaseRobots      = "robots"      // 生成 robots.txt
+	PhaseProfile     = "profile"     // 生成 README.md
+	PhaseMinify      = "minify"      // 压缩暂存区中的内容
+	PhaseCompress    = "compress"    // 生成预压缩的 .gz 文件
+	PhaseCheck       = "check"       // 检测站内链接
+	PhaseCommit      = "commit"      // 将暂存区的内容提交到 Builder.Dest
+)
+
+// Event 编译过程中的事件
+type Event struct {
+	Type EventType
+	Time time.Time // 事件发生的时间
+
+	// 当前所处的阶段
+	//
+	// 仅在 EventPhaseStart、EventPhaseEnd、EventWrite 和 EventRemove 中有值。
+	Phase string
+
+	// 写入或删除的文件
+	//
+	// 仅在 EventWrite 和 EventRemove 中有值，Size 为写入的字节数。
+	Path string
+	Size int
+
+	// 阶段或是整个编译过程的耗时
+	//
+	// 仅在 EventPhaseEnd 和 EventEnd 中有值。
+	Duration time.Duration
+
+	// 阶段或是整个编译过程返回的错误
+	//
+	// 仅在 EventPhaseEnd 和 EventEnd 中可能有值。
+	Err error
+}
+
+func (b *Builder) emit(e *Event) {
+	if b.OnEvent != nil {
+		e.Time = time.Now()
+		b.OnEvent(e)
+	}
+}
+
+// 执行 name 指定的阶段
+//
+// 如果 ctx 已经取消，不再执行并返回 ctx.Err()。
+func (b *Builder) phase(ctx context.Context, name string, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	b.emit(&Event{Type: EventPhaseStart, Phase: name})
+	err := f()
+	dur := time.Since(start)
+	b.emit(&Event{Type: EventPhaseEnd, Phase: name, Duration: dur, Err: err})
+	if b.current != nil {
+		b.current.Phases = append(b.current.Phases, &PhaseResult{Name: name, Duration: dur})
+	}
+
+	return err
+}
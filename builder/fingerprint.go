@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/caixw/blogit/v2/internal/data"
+	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/minify"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// 文件名中内容哈希的长度
+const fingerprintSize = 10
+
+// 生成了资源指纹的文件
+type fingerprint struct {
+	URL       string
+	Integrity string // 子资源完整性校验值，未开启时为空。
+}
+
+type fingerprints struct {
+	assets map[string]*fingerprint // 以 themeURL 的参数作为键名
+	paths  map[string]struct{}     // 生成的文件路径
+}
+
+func newFingerprints() *fingerprints {
+	return &fingerprints{
+		assets: make(map[string]*fingerprint, 10),
+		paths:  make(map[string]struct{}, 10),
+	}
+}
+
+// 获取 p 对应的资源指纹，p 为相对于 themes 的路径，比如 default/style.css。
+//
+// 不存在返回 nil。
+func (fp *fingerprints) get(p string) *fingerprint {
+	if fp == nil {
+		return nil
+	}
+	return fp.assets[strings.TrimPrefix(p, "/")]
+}
+
+// 是否为生成的文件，p 为相对于暂存区的路径。
+func (fp *fingerprints) generated(p string) bool {
+	if fp == nil {
+		return false
+	}
+	_, found := fp.paths[p]
+	return found
+}
+
+// 为当前主题中的 CSS 和 JS 文件以及代码高亮的 CSS 文件生成带内容哈希的副本
+//
+// 原文件依然保留，主题可以通过 assetURL 获取副本的地址。
+// 开启了压缩的，副本会先进行压缩，且之后不会再被 buildMinify 处理，保证哈希值与内容相符。
+// 所有页面的生成都依赖此阶段的结果，所以必须在生成页面之前执行。
+func (b *Builder) buildFingerprint(d *data.Data) error {
+	if d.Fingerprint == nil {
+		return nil
+	}
+
+	paths, err := walkFiles(b.stage)
+	if err != nil {
+		return err
+	}
+
+	// 仅处理当前主题，其它主题中的文件不会被页面引用。
+	dir := path.Join(vars.ThemesDir, d.Theme.ID) + "/"
+	for _, p := range paths {
+		if !strings.HasPrefix(p, dir) {
+			continue
+		}
+		ext := strings.ToLower(path.Ext(p))
+		if ext != ".css" && ext != ".js" {
+			continue
+		}
+
+		content, err := fs.ReadFile(b.stage, p)
+		if err != nil {
+			return err
+		}
+		if m := d.Minify; m != nil && !isMinified(p) {
+			switch {
+			case ext == ".css" && m.CSS:
+				content = minify.CSS(content)
+			case ext == ".js" && m.JS:
+				content = minify.JS(content)
+			}
+		}
+
+		h := loader.Hash(content)[:fingerprintSize]
+		fpPath := strings.TrimSuffix(p, path.Ext(p)) + "." + h + path.Ext(p)
+		if err := b.appendFile(fpPath, source{typ: SourceGenerated, name: PhaseFingerprint}, content); err != nil {
+			return err
+		}
+
+		b.fingerprints.paths[fpPath] = struct{}{}
+		b.fingerprints.assets[strings.TrimPrefix(p, vars.ThemesDir+"/")] = &fingerprint{
+			URL:       data.BuildURL(d.URL, fpPath),
+			Integrity: integrity(d.Fingerprint.Integrity, content),
+		}
+	}
+
+	for i, h := range d.Highlights {
+		if a := b.fingerprints.get(strings.TrimPrefix(h.Path, vars.ThemesDir+"/")); a != nil {
+			b.sites[d.Language].Highlights[i].URL = a.URL
+			b.sites[d.Language].Highlights[i].Integrity = a.Integrity
+		}
+	}
+
+	return nil
+}
+
+// 计算 content 的子资源完整性校验值，algo 为空时返回空值。
+func integrity(algo string, content []byte) string {
+	var h hash.Hash
+	switch algo {
+	case "sha256":
+		h = sha256.New()
+	case "sha384":
+		h = sha512.New384()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return ""
+	}
+
+	h.Write(content)
+	return algo + "-" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
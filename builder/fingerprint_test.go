@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestBuilder_buildFingerprint(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	conf := src[vars.ConfYAML].Data
+
+	b := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+	html, err := fs.ReadFile(b.Dest, "index"+vars.Ext)
+	a.NotError(err).
+		Contains(string(html), `/themes/default/style.css"`).
+		NotContains(string(html), "integrity=")
+
+	src[vars.ConfYAML] = &fstest.MapFile{Data: append(conf, []byte("\nfingerprint:\n  integrity: sha384\nminify:\n  css: true\n")...)}
+	src["themes/other/style.css"] = &fstest.MapFile{Data: []byte("body{}")}
+	a.NotError(b.Rebuild())
+
+	// 未使用的主题
+	others, err := fs.Glob(b.Dest, "themes/other/style.*.css")
+	a.NotError(err).Empty(others)
+
+	matches, err := fs.Glob(b.Dest, "themes/default/style.*.css")
+	a.NotError(err).Length(matches, 1)
+	css, err := fs.ReadFile(b.Dest, matches[0])
+	a.NotError(err)
+	sum := sha512.Sum384(css)
+	sri := "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+
+	// html/template 会将属性中的 + 转义为 &#43;
+	html, err = fs.ReadFile(b.Dest, "index"+vars.Ext)
+	a.NotError(err).
+		Contains(string(html), "/"+matches[0]+`"`).
+		Contains(string(html), `integrity="`+strings.ReplaceAll(sri, "+", "&#43;")+`"`).
+		Contains(string(html), `/themes/default/solarized-light.`) // 代码高亮
+	a.FileExistsFS(b.Dest, "themes/default/style.css") // 原文件依然保留
+
+	// 压缩之后不会再被修改
+	orig, err := fs.ReadFile(b.Dest, "themes/default/style.css")
+	a.NotError(err).Equal(orig, css)
+
+	matches, err = fs.Glob(b.Dest, "themes/default/nav.*.js")
+	a.NotError(err).Length(matches, 1)
+	a.True(strings.HasPrefix(path.Base(matches[0]), "nav.")).
+		Contains(string(html), "/"+matches[0]+`"`)
+}
+
+func TestIntegrity(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Empty(integrity("", []byte("abc"))).
+		Equal(integrity("sha256", []byte("abc")), "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=")
+}
@@ -5,11 +5,18 @@
 package builder
 
 import (
+	"encoding/json"
+	"errors"
 	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"sync"
 
 	"github.com/psanford/memfs"
+
+	"github.com/caixw/blogit/v2/internal/vars"
 )
 
 // WritableFS 带有写入功能的文件系统
@@ -33,27 +40,136 @@ type WritableFS interface {
 	Reset() error
 }
 
+// RemovableFS 可删除文件的 WritableFS
+//
+// 上一次编译生成而本次编译不再生成的文件，会通过 Remove 进行删除。
+// 同时实现了 PrunableFS 的，优先使用 PrunableFS。两者都未实现的 WritableFS，
+// 在非增量模式下会在写入之前调用 Reset 清空内容，增量模式下则会保留这些文件。
+type RemovableFS interface {
+	WritableFS
+
+	// Remove 删除由 WriteFile 写入的文件
+	//
+	// 文件不存在时不应该返回错误。
+	Remove(path string) error
+}
+
+// PrunableFS 可清理过期内容的 WritableFS
+//
+// 每次提交到 Builder.Dest 之后，会调用 Prune 删除本次编译不再生成的内容。
+// 与 RemovableFS 不同，PrunableFS 自行记录写入的文件，不依赖于 Builder 的缓存，
+// 所以即使是一个全新的 Builder 实例，也能删除之前的编译留下的过期内容。
+type PrunableFS interface {
+	WritableFS
+
+	// Prune 删除由 WriteFile 写入但是不在 keep 中的文件
+	//
+	// 因此而变为空的目录也应该一并删除，非 WriteFile 写入的内容则不受影响。
+	// 返回被删除的文件列表。
+	Prune(keep []string) ([]string, error)
+}
+
 // MemoryFS 返回以内存作为保存对象的文件系统
-func MemoryFS() WritableFS { return &memoryFS{FS: memfs.New()} }
+func MemoryFS() WritableFS { return newMemoryFS() }
 
 // DirFS 返回以普通目录作为保存对象的文件系统
+//
+// 通过 WriteFile 写入的文件以及因此创建的目录，会记录在与 dir 同级的 .<dir>.blogit-files.json 中，
+// 之后的 Reset 和 Prune 操作，即使在不同的进程中，也只会删除这些记录的内容。
+// 记录文件不放在 dir 之下，以免被当作网站的内容一起发布。
 func DirFS(dir string) WritableFS {
 	return &dirFS{
-		FS:    os.DirFS(dir),
-		dir:   dir,
-		files: make([]string, 0, 10),
+		FS:     os.DirFS(dir),
+		dir:    dir,
+		record: recordPath(dir),
 	}
 }
 
-// 内存文件系统，每次创建都是新的，不存在与 dirFS 一样的问题。
+// 返回 dir 对应的记录文件路径
+func recordPath(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Join(filepath.Dir(dir), "."+filepath.Base(dir)+vars.DirFSRecordJSON)
+}
+
+// 内存文件系统，每次创建都是新的，不需要记录写入的文件。
 type memoryFS struct {
-	*memfs.FS
+	mux sync.RWMutex // 保护 fs 的替换操作
+	fs  *memfs.FS
 }
 
+func newMemoryFS() *memoryFS { return &memoryFS{fs: memfs.New()} }
+
 type dirFS struct {
 	fs.FS
-	dir   string
-	files []string
+	dir    string
+	record string // 记录文件的路径
+
+	loadOnce sync.Once
+	loadErr  error
+
+	// 由 WriteFile 写入的文件和创建的目录，均为相对于 dir 的路径。
+	files    map[string]struct{}
+	dirs     map[string]struct{}
+	filesMux sync.Mutex
+}
+
+// dirFS 的记录文件格式
+type dirRecord struct {
+	Files []string `json:"files"`
+	Dirs  []string `json:"dirs"`
+}
+
+// 加载记录文件，多次调用只会加载一次。
+func (dir *dirFS) load() error {
+	dir.loadOnce.Do(func() {
+		dir.files = make(map[string]struct{}, 100)
+		dir.dirs = make(map[string]struct{}, 10)
+
+		data, err := os.ReadFile(dir.record)
+		if errors.Is(err, fs.ErrNotExist) {
+			return
+		} else if err != nil {
+			dir.loadErr = err
+			return
+		}
+
+		r := &dirRecord{}
+		if err := json.Unmarshal(data, r); err != nil {
+			dir.loadErr = err
+			return
+		}
+		for _, f := range r.Files {
+			dir.files[f] = struct{}{}
+		}
+		for _, d := range r.Dirs {
+			dir.dirs[d] = struct{}{}
+		}
+	})
+	return dir.loadErr
+}
+
+// 保存记录文件，如果没有任何记录，则删除记录文件。
+func (dir *dirFS) save() error {
+	p := dir.record
+	if len(dir.files) == 0 && len(dir.dirs) == 0 {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	r := &dirRecord{Files: sortedKeys(dir.files), Dirs: sortedKeys(dir.dirs)}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, os.ModePerm)
 }
 
 func (dir *dirFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
@@ -61,39 +177,202 @@ func (dir *dirFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
 		return &fs.PathError{Op: "close", Path: name, Err: fs.ErrInvalid}
 	}
 
+	if err := dir.load(); err != nil {
+		return err
+	}
+
+	// 记录需要创建的目录
+	created := make([]string, 0, 5)
+	for d := path.Dir(name); d != "."; d = path.Dir(d) {
+		if _, err := os.Stat(path.Join(dir.dir, d)); err == nil {
+			break
+		}
+		created = append(created, d)
+	}
+
 	p := path.Join(dir.dir, name)
 	if err := os.MkdirAll(path.Dir(p), perm); err != nil {
 		return err
 	}
 
-	if err := os.WriteFile(p, data, perm); err != nil {
+	dir.filesMux.Lock()
+	for _, d := range created {
+		dir.dirs[d] = struct{}{}
+	}
+	dir.filesMux.Unlock()
+
+	// 先写入临时文件再重命名，保证读取者不会读到写了一半的内容。
+	tmp, err := os.CreateTemp(path.Dir(p), "."+path.Base(p)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if err1 := tmp.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
 		return err
 	}
 
-	// BUG(caixw): 仅记录了文件，但是并未记录文件的父目录结构。
-	// 在 Reset 删除时，可能会留下一堆空目录。
-	dir.files = append(dir.files, p) // 文件写入成功之后，记录添加的文件
+	dir.filesMux.Lock()
+	dir.files[name] = struct{}{} // 文件写入成功之后，记录添加的文件
+	dir.filesMux.Unlock()
 
 	return nil
 }
 
 func (dir *dirFS) Reset() error {
-	for _, f := range dir.files {
-		if err := os.RemoveAll(f); err != nil {
+	_, err := dir.Prune(nil)
+	return err
+}
+
+// 同时删除记录中已经为空的目录，并保存记录文件。
+func (dir *dirFS) Prune(keep []string) ([]string, error) {
+	if err := dir.load(); err != nil {
+		return nil, err
+	}
+
+	dir.filesMux.Lock()
+	defer dir.filesMux.Unlock()
+
+	k := make(map[string]struct{}, len(keep))
+	for _, p := range keep {
+		k[p] = struct{}{}
+	}
+
+	removed := make([]string, 0, 10)
+	for f := range dir.files {
+		if _, found := k[f]; found {
+			continue
+		}
+
+		if err := os.Remove(path.Join(dir.dir, f)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		delete(dir.files, f)
+		removed = append(removed, f)
+	}
+	sort.Strings(removed)
+
+	if err := dir.removeEmptyDirs(); err != nil {
+		return nil, err
+	}
+	return removed, dir.save()
+}
+
+// 删除记录中的空目录
+//
+// 调用者需要自行加锁。
+func (dir *dirFS) removeEmptyDirs() error {
+	dirs := sortedKeys(dir.dirs)
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs))) // 子目录排在父目录之前
+
+	for _, d := range dirs {
+		p := path.Join(dir.dir, d)
+		entries, err := os.ReadDir(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			delete(dir.dirs, d)
+			continue
+		} else if err != nil {
 			return err
 		}
+
+		if len(entries) == 0 {
+			if err := os.Remove(p); err != nil {
+				return err
+			}
+			delete(dir.dirs, d)
+		}
 	}
 	return nil
 }
 
+func (dir *dirFS) Remove(name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if err := dir.load(); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path.Join(dir.dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	dir.filesMux.Lock()
+	defer dir.filesMux.Unlock()
+	delete(dir.files, name)
+	return dir.removeEmptyDirs()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *memoryFS) Open(name string) (fs.File, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	return m.fs.Open(name)
+}
+
 func (m *memoryFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
-	if err := m.FS.MkdirAll(path.Dir(name), perm); err != nil {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	if err := m.fs.MkdirAll(path.Dir(name), perm); err != nil {
 		return err
 	}
-	return m.FS.WriteFile(name, data, perm)
+	return m.fs.WriteFile(name, data, perm)
 }
 
 func (m *memoryFS) Reset() error {
-	m.FS = memfs.New()
+	m.swap(newMemoryFS())
+	return nil
+}
+
+// 以 src 的内容替换当前的内容
+//
+// 替换之后 src 不应该再被使用。
+func (m *memoryFS) swap(src *memoryFS) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.fs = src.fs
+}
+
+// memfs 并未提供删除功能，只能将除 name 之外的文件复制到一个新的实例中。
+func (m *memoryFS) Remove(name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+
+	mfs := newMemoryFS()
+	err := fs.WalkDir(m, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || p == name {
+			return err
+		}
+
+		data, err := fs.ReadFile(m, p)
+		if err != nil {
+			return err
+		}
+		return mfs.WriteFile(p, data, fs.ModePerm)
+	})
+	if err != nil {
+		return err
+	}
+
+	m.swap(mfs)
 	return nil
 }
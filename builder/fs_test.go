@@ -10,16 +10,19 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 
 	"github.com/issue9/assert/v4"
 
 	"github.com/caixw/blogit/v2/internal/filesystem"
+	"github.com/caixw/blogit/v2/internal/vars"
 )
 
 var (
-	_ WritableFS = &memoryFS{}
-	_ WritableFS = &dirFS{}
+	_ RemovableFS = &memoryFS{}
+	_ RemovableFS = &dirFS{}
+	_ PrunableFS  = &dirFS{}
 )
 
 func testWritableFS(wfs WritableFS, a *assert.Assertion) {
@@ -57,6 +60,16 @@ func testWritableFS(wfs WritableFS, a *assert.Assertion) {
 	a.Error(err)
 	err = wfs.WriteFile("/dir1/file.png", []byte{1, 2, 3}, fs.ModePerm)
 	a.Error(err)
+
+	// 删除文件
+	if rm, ok := wfs.(RemovableFS); ok {
+		a.NotError(rm.WriteFile("dir1/dir2/file.png", []byte{1, 2, 3}, fs.ModePerm))
+		a.NotError(rm.Remove("dir1/file.png"))
+		a.False(filesystem.Exists(rm, "dir1/file.png")).
+			True(filesystem.Exists(rm, "dir1/dir2/file.png"))
+		a.NotError(rm.Remove("dir1/not-exists.png"))
+		a.Error(rm.Remove("/dir1/file.png"))
+	}
 }
 
 func TestWritableFS(t *testing.T) {
@@ -91,3 +104,54 @@ func TestDir(t *testing.T) {
 	_, err = os.Stat(obj)
 	a.True(err == nil || errors.Is(err, fs.ErrExist))
 }
+
+func TestDirFS_Prune(t *testing.T) {
+	a := assert.New(t, false)
+	dir := t.TempDir()
+
+	a.NotError(os.Mkdir(filepath.Join(dir, "posts"), os.ModePerm))
+	a.NotError(os.WriteFile(filepath.Join(dir, "posts", "keep.txt"), []byte{1}, os.ModePerm))
+
+	inst := DirFS(dir).(PrunableFS)
+	a.NotError(inst.WriteFile("posts/p1.html", []byte{1}, os.ModePerm))
+	a.NotError(inst.WriteFile("posts/2020/12/p2.html", []byte{1}, os.ModePerm))
+	a.NotError(inst.WriteFile("tags/t1.html", []byte{1}, os.ModePerm))
+	a.NotError(inst.WriteFile("index.html", []byte{1}, os.ModePerm))
+	removed, err := inst.Prune([]string{"posts/p1.html", "posts/2020/12/p2.html", "tags/t1.html", "index.html"})
+	a.NotError(err).Empty(removed).
+		FileExists(recordPath(dir)).
+		FileNotExists(filepath.Join(dir, vars.DirFSRecordJSON)) // 记录文件不在输出目录之下
+
+	// 新的实例，依然可以删除之前写入的文件。
+	inst = DirFS(dir).(PrunableFS)
+	a.NotError(inst.WriteFile("index.html", []byte{2}, os.ModePerm))
+	removed, err = inst.Prune([]string{"index.html", "posts/p1.html"})
+	a.NotError(err).Equal(removed, []string{"posts/2020/12/p2.html", "tags/t1.html"})
+	a.True(filesystem.Exists(inst, "index.html")).
+		True(filesystem.Exists(inst, "posts/p1.html")).
+		True(filesystem.Exists(inst, "posts/keep.txt")).
+		False(filesystem.Exists(inst, "posts/2020")). // 空目录也被删除
+		False(filesystem.Exists(inst, "tags"))
+
+	// Reset 之后，仅保留原有的内容。
+	inst = DirFS(dir).(PrunableFS)
+	a.NotError(inst.Reset())
+	a.False(filesystem.Exists(inst, "index.html")).
+		False(filesystem.Exists(inst, "posts/p1.html")).
+		True(filesystem.Exists(inst, "posts/keep.txt")).
+		FileNotExists(recordPath(dir))
+
+	// 记录文件格式错误
+	a.NotError(os.WriteFile(recordPath(dir), []byte("xx"), os.ModePerm))
+	inst = DirFS(dir).(PrunableFS)
+	a.Error(inst.WriteFile("index.html", []byte{2}, os.ModePerm))
+}
+
+func TestRecordPath(t *testing.T) {
+	a := assert.New(t, false)
+
+	wd, err := os.Getwd()
+	a.NotError(err)
+	a.Equal(recordPath("./dest/"), filepath.Join(wd, ".dest"+vars.DirFSRecordJSON)).
+		Equal(recordPath("."), filepath.Join(filepath.Dir(wd), "."+filepath.Base(wd)+vars.DirFSRecordJSON))
+}
@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"encoding/json"
+	"io/fs"
+	"sort"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+)
+
+// ManifestEntry.Type 的各类值
+const (
+	SourceStatic    = "static"    // 静态文件，Source 为源文件的路径；
+	SourcePost      = "post"      // 文章，Source 为文章的 slug；
+	SourceTemplate  = "template"  // 除文章之外由模板生成的页面，Source 为模板名称；
+	SourceGenerated = "generated" // 其它生成的文件，Source 为生成该文件的阶段，即 Phase 开头的常量。
+)
+
+// Manifest 编译的清单
+//
+// 记录了编译输出的所有文件，可通过 Builder.ManifestFile 写入 Builder.Dest。
+type Manifest struct {
+	Files map[string]*ManifestEntry `json:"files"` // 以输出的文件路径为键名
+}
+
+// ManifestEntry 清单中的文件信息
+type ManifestEntry struct {
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+	Type   string `json:"type"`   // 文件的来源类型，Source 开头的常量；
+	Source string `json:"source"` // 文件的来源，其值根据 Type 而不同。
+}
+
+// 文件的来源
+type source struct {
+	typ, name string
+}
+
+// LoadManifest 从 fsys 中加载清单文件
+func LoadManifest(fsys fs.FS, name string) (*Manifest, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Diff 与 prev 进行比较
+//
+// 返回相对于 prev 新增、内容有变化以及被删除的文件路径，均已按路径排序。
+// prev 可以为空，表示所有文件都是新增的。
+func (m *Manifest) Diff(prev *Manifest) (added, changed, removed []string) {
+	var prevFiles map[string]*ManifestEntry
+	if prev != nil {
+		prevFiles = prev.Files
+	}
+
+	for p, e := range m.Files {
+		if pe, found := prevFiles[p]; !found {
+			added = append(added, p)
+		} else if pe.SHA256 != e.SHA256 || pe.Size != e.Size {
+			changed = append(changed, p)
+		}
+	}
+
+	for p := range prevFiles {
+		if _, found := m.Files[p]; !found {
+			removed = append(removed, p)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return added, changed, removed
+}
+
+// 根据暂存区的内容生成清单并写入暂存区
+//
+// paths 为暂存区中的文件列表，返回值为添加了清单文件之后的列表。
+func (b *Builder) appendManifest(paths []string) ([]string, error) {
+	m := &Manifest{Files: make(map[string]*ManifestEntry, len(paths))}
+	for _, p := range paths {
+		data, err := fs.ReadFile(b.stage, p)
+		if err != nil {
+			return nil, err
+		}
+
+		src := b.sources[p]
+		m.Files[p] = &ManifestEntry{
+			Size:   len(data),
+			SHA256: loader.Hash(data),
+			Type:   src.typ,
+			Source: src.name,
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.stage.WriteFile(b.ManifestFile, data, fs.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return append(paths, b.ManifestFile), nil
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestManifest_Diff(t *testing.T) {
+	a := assert.New(t, false)
+
+	prev := &Manifest{Files: map[string]*ManifestEntry{
+		"a.html": {Size: 1, SHA256: "1"},
+		"b.html": {Size: 2, SHA256: "2"},
+		"c.html": {Size: 3, SHA256: "3"},
+	}}
+	m := &Manifest{Files: map[string]*ManifestEntry{
+		"a.html": {Size: 1, SHA256: "1"},
+		"c.html": {Size: 3, SHA256: "33"},
+		"e.html": {Size: 5, SHA256: "5"},
+		"d.html": {Size: 4, SHA256: "4"},
+	}}
+
+	added, changed, removed := m.Diff(prev)
+	a.Equal(added, []string{"d.html", "e.html"}).
+		Equal(changed, []string{"c.html"}).
+		Equal(removed, []string{"b.html"})
+
+	added, changed, removed = m.Diff(nil)
+	a.Equal(added, []string{"a.html", "c.html", "d.html", "e.html"}).
+		Empty(changed).
+		Empty(removed)
+
+	added, changed, removed = m.Diff(m)
+	a.Empty(added).Empty(changed).Empty(removed)
+}
+
+func TestBuilder_ManifestFile(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+
+	b := &Builder{Src: src, Dest: DirFS(t.TempDir()), ManifestFile: vars.ManifestJSON}
+	a.NotError(b.Rebuild())
+	m1, err := LoadManifest(b.Dest, vars.ManifestJSON)
+	a.NotError(err).NotNil(m1)
+
+	a.Equal(m1.Files["posts/p1"+vars.Ext].Type, SourcePost).
+		Equal(m1.Files["posts/p1"+vars.Ext].Source, "posts/p1").
+		Equal(m1.Files["index"+vars.Ext].Type, SourceTemplate).
+		Equal(m1.Files["index"+vars.Ext].Source, vars.IndexTemplate).
+		Equal(m1.Files["themes/default/style.css"].Type, SourceStatic).
+		Equal(m1.Files["themes/default/style.css"].Source, "themes/default/style.css").
+		Equal(m1.Files[vars.RssXML].Type, SourceGenerated).
+		Equal(m1.Files[vars.RssXML].Source, PhaseRSS).
+		NotEmpty(m1.Files[vars.RssXML].SHA256).
+		NotZero(m1.Files[vars.RssXML].Size)
+	a.NotContains(m1.Files, vars.ManifestJSON)
+
+	delete(src, "posts/p1.md")
+	src["themes/default/style.css"] = &fstest.MapFile{Data: []byte("body{}")}
+	a.NotError(b.Rebuild())
+	m2, err := LoadManifest(b.Dest, vars.ManifestJSON)
+	a.NotError(err).NotNil(m2)
+
+	added, changed, removed := m2.Diff(m1)
+	a.Empty(added).
+		Contains(changed, "themes/default/style.css").
+		Contains(removed, "posts/p1"+vars.Ext)
+
+	// 未指定 ManifestFile
+	b = &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+	_, err = LoadManifest(b.Dest, vars.ManifestJSON)
+	a.Error(err)
+}
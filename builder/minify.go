@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/caixw/blogit/v2/internal/data"
+	"github.com/caixw/blogit/v2/internal/minify"
+	"github.com/caixw/blogit/v2/internal/pool"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// 压缩暂存区中的文件
+//
+// 包括静态文件在内的所有内容都已经写入暂存区，所以只能在最后统一处理。
+// XML 由 appendXMLFile 在生成时直接输出压缩的内容，资源指纹的文件由 buildFingerprint 压缩。
+func (b *Builder) buildMinify(d *data.Data) error {
+	if d.Minify == nil {
+		return nil
+	}
+
+	minifiers := make(map[string]func([]byte) []byte, 5)
+	if d.Minify.HTML {
+		minifiers[vars.Ext] = minify.HTML
+		minifiers[".htm"] = minify.HTML
+	}
+	if d.Minify.CSS {
+		minifiers[".css"] = minify.CSS
+	}
+	if d.Minify.JS {
+		minifiers[".js"] = minify.JS
+		minifiers[".mjs"] = minify.JS
+	}
+	if d.Minify.JSON {
+		minifiers[".json"] = minify.JSON
+	}
+	if len(minifiers) == 0 {
+		return nil
+	}
+
+	paths, err := walkFiles(b.stage)
+	if err != nil {
+		return err
+	}
+
+	return pool.Run(b.ctx, b.Concurrency, len(paths), func(i int) error {
+		p := paths[i]
+		f, found := minifiers[strings.ToLower(path.Ext(p))]
+		if !found || isMinified(p) || b.fingerprints.generated(p) {
+			return nil
+		}
+
+		data, err := fs.ReadFile(b.stage, p)
+		if err != nil {
+			return err
+		}
+		return b.stage.WriteFile(p, f(data), fs.ModePerm)
+	})
+}
+
+// 是否为已经压缩过的文件，比如 jquery.min.js
+func isMinified(p string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(strings.ToLower(p), path.Ext(p)), ".min")
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestBuilder_buildMinify(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	src["themes/default/lib.min.js"] = &fstest.MapFile{Data: []byte("let a = 1\n\n\n")}
+
+	read := func(b *Builder, p string) []byte {
+		data, err := fs.ReadFile(b.Dest, p)
+		a.NotError(err)
+		return data
+	}
+
+	b := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+	html := read(b, "index"+vars.Ext)
+	css := read(b, "themes/default/style.css")
+	rss := read(b, vars.RssXML)
+	a.True(bytes.Contains(rss, []byte("\n\t")))
+
+	conf := src[vars.ConfYAML].Data
+	src[vars.ConfYAML] = &fstest.MapFile{Data: append(conf, []byte("\nminify:\n  html: true\n  css: true\n  js: true\n  xml: true\n")...)}
+	a.NotError(b.Rebuild())
+	a.True(len(read(b, "index"+vars.Ext)) < len(html)).
+		True(len(read(b, "themes/default/style.css")) < len(css)).
+		False(bytes.Contains(read(b, vars.RssXML), []byte("\n\t"))).
+		Equal(read(b, "themes/default/lib.min.js"), []byte("let a = 1\n\n\n")) // 已经压缩过的文件
+
+	// 单独关闭 css
+	src[vars.ConfYAML] = &fstest.MapFile{Data: append(conf, []byte("\nminify:\n  html: true\n")...)}
+	a.NotError(b.Rebuild())
+	a.True(len(read(b, "index"+vars.Ext)) < len(html)).
+		Equal(read(b, "themes/default/style.css"), css).
+		Equal(read(b, vars.RssXML)[:100], rss[:100])
+}
@@ -5,22 +5,30 @@
 package builder
 
 import (
+	"slices"
+
 	"github.com/caixw/blogit/v2/internal/data"
 	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/pool"
 	"github.com/caixw/blogit/v2/internal/vars"
 )
 
+// 同时生成不公开的文章
 func (b *Builder) buildPosts(d *data.Data) error {
-	for _, p := range d.Posts {
-		page := b.page(p.Template)
+	posts := append(slices.Clip(d.Posts), d.Unlisted...)
+	return pool.Run(b.ctx, b.Concurrency, len(posts), func(i int) error {
+		p := posts[i]
+		page := b.page(d, p.Template)
 		page.Title = p.Title + d.TitleSuffix
 		page.Permalink = p.Permalink
 		page.Keywords = p.Keywords
 		page.Description = p.Summary
-		page.Language = d.Language
+		page.Language = p.Language
 		page.Post = p
 		page.JSONLD = p.JSONLD
 		page.License = p.License
+		page.NoIndex = p.Unlisted
+		page.Translations = postTranslations(p)
 
 		if p.Next != nil {
 			page.Next = &loader.Link{
@@ -35,17 +43,33 @@ func (b *Builder) buildPosts(d *data.Data) error {
 			}
 		}
 
-		if err := b.appendTemplateFile(p.Path, page); err != nil {
-			return err
-		}
-	}
+		return b.appendTemplateFile(p.Path, page)
+	})
+}
+
+func (b *Builder) buildPages(d *data.Data) error {
+	return pool.Run(b.ctx, b.Concurrency, len(d.Pages), func(i int) error {
+		p := d.Pages[i]
+		page := b.page(d, p.Template)
+		page.Title = p.Title + d.TitleSuffix
+		page.Permalink = p.Permalink
+		page.Keywords = p.Keywords
+		page.Description = p.Summary
+		page.Language = p.Language
+		page.Post = p
+		page.JSONLD = p.JSONLD
+		page.License = p.License
+		page.NoIndex = p.Unlisted
+		page.Translations = postTranslations(p)
 
-	return nil
+		return b.appendTemplateFile(p.Path, page)
+	})
 }
 
 func (b *Builder) buildIndexes(d *data.Data) error {
-	for _, index := range d.Indexes {
-		page := b.page(vars.IndexTemplate)
+	return pool.Run(b.ctx, b.Concurrency, len(d.Indexes), func(i int) error {
+		index := d.Indexes[i]
+		page := b.page(d, vars.IndexTemplate)
 		if index.Index == 1 {
 			page.Title = d.Title
 		} else {
@@ -56,6 +80,9 @@ func (b *Builder) buildIndexes(d *data.Data) error {
 		page.Description = index.Description
 		page.Language = d.Language
 		page.Index = index
+		if index.Index == 1 {
+			page.Translations = indexTranslations(d)
+		}
 
 		if index.Next != nil {
 			page.Next = &loader.Link{
@@ -70,10 +97,11 @@ func (b *Builder) buildIndexes(d *data.Data) error {
 			}
 		}
 
-		if err := b.appendTemplateFile(index.Path, page); err != nil {
-			return err
-		}
-	}
+		return b.appendTemplateFile(index.Path, page)
+	})
+}
 
-	return nil
+// 其它语言的首页
+func indexTranslations(d *data.Data) []*translation {
+	return translations(d, func(l *data.Data) (string, string) { return l.Title, l.URL })
 }
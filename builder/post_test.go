@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestBuilder_buildPages(t *testing.T) {
+	a := assert.New(t, false)
+	b := &Builder{Src: testdata.MapFS(t), Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+
+	read := func(p string) string {
+		data, err := fs.ReadFile(b.Dest, p)
+		a.NotError(err)
+		return string(data)
+	}
+
+	a.Contains(read("about"+vars.Ext), "这是一个通过 blogit 生成的网站").
+		Contains(read(vars.SitemapXML), "https://example.com/about.html").
+		NotContains(read(vars.RssXML), "https://example.com/about.html").
+		NotContains(read(vars.AtomXML), "https://example.com/about.html").
+		Contains(read(vars.IndexFilename), `href="https://example.com/about.html"`) // 菜单
+}
+
+func TestBuilder_unlisted(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	src["posts/unlisted.md"] = &fstest.MapFile{Data: []byte("---\ntitle: unlisted\ncreated: 2021-01-01T00:00:00Z\nstate: unlisted\ntags: [api]\n---\n")}
+	b := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+
+	read := func(p string) string {
+		data, err := fs.ReadFile(b.Dest, p)
+		a.NotError(err)
+		return string(data)
+	}
+
+	const link = "https://example.com/posts/unlisted.html"
+	a.Contains(read("posts/unlisted"+vars.Ext), `<meta name="robots" content="noindex" />`).
+		NotContains(read("posts/p1"+vars.Ext), `<meta name="robots"`).
+		NotContains(read(vars.SitemapXML), link).
+		NotContains(read(vars.RssXML), link).
+		NotContains(read(vars.AtomXML), link).
+		NotContains(read(vars.IndexFilename), link).
+		NotContains(read(vars.ArchiveFilename), link).
+		NotContains(read("tags/api"+vars.Ext), link)
+}
+
+func TestBuilder_bundle(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	src["posts/2024/bundle/index.md"] = &fstest.MapFile{Data: []byte("---\ntitle: bundle\ncreated: 2024-01-01T00:00:00Z\ntags: [api]\nsummary: <img src=\"img.png\" />\n---\n![img](img.png)\n\n[missing](not-exists.png)\n")}
+	src["posts/2024/bundle/img.png"] = &fstest.MapFile{Data: []byte("png")}
+	b := &Builder{Src: src, Dest: MemoryFS(), CheckLinks: true}
+	a.NotError(b.Rebuild())
+
+	read := func(p string) string {
+		data, err := fs.ReadFile(b.Dest, p)
+		a.NotError(err)
+		return string(data)
+	}
+
+	a.FileExistsFS(b.Dest, "posts/2024/bundle/img.png").
+		Contains(read("posts/2024/bundle/index"+vars.Ext), `src="img.png"`).
+		Contains(read(vars.RssXML), "https://example.com/posts/2024/bundle/img.png").
+		Contains(read(vars.AtomXML), "https://example.com/posts/2024/bundle/img.png")
+
+	broken := make([]*BrokenLink, 0, 1)
+	for _, l := range b.Result().Links.Broken {
+		if l.Page == "posts/2024/bundle/index.html" && l.Line > 0 {
+			broken = append(broken, l)
+		}
+	}
+	a.Equal(broken, []*BrokenLink{
+		{Page: "posts/2024/bundle/index.html", Post: "posts/2024/bundle/index.md", Line: 9, Target: "not-exists.png"},
+	})
+}
+
+func TestBuilder_permalinks(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	src["conf.yaml"].Data = append(src["conf.yaml"].Data, []byte(`
+permalinks:
+  post: /:year/:month/:slug/
+  tag: /tag/:slug/
+  index: /page/:page/
+`)...)
+	b := &Builder{Src: src, Dest: MemoryFS(), CheckLinks: true}
+	a.NotError(b.Rebuild())
+
+	read := func(p string) string {
+		data, err := fs.ReadFile(b.Dest, p)
+		a.NotError(err)
+		return string(data)
+	}
+
+	a.FileExistsFS(b.Dest, "2020/01/p1/index.html").
+		FileExistsFS(b.Dest, "tag/api/index.html").
+		FileExistsFS(b.Dest, "page/2/index.html").
+		FileNotExistsFS(b.Dest, "posts/p1.html").
+		FileNotExistsFS(b.Dest, "tags/api.html").
+		FileNotExistsFS(b.Dest, "index-2.html")
+
+	a.Contains(read(vars.SitemapXML), "<loc>https://example.com/2020/01/p1/</loc>").
+		Contains(read(vars.SitemapXML), "<loc>https://example.com/tag/api/</loc>").
+		Contains(read(vars.RssXML), "https://example.com/2020/01/p1/").
+		Contains(read(vars.AtomXML), "https://example.com/2020/01/p1/").
+		Contains(read("2020/01/p1/index.html"), `<link rel="canonical" href="https://example.com/2020/01/p1/" />`)
+
+	for _, l := range b.Result().Links.Broken {
+		a.NotContains(l.Target, "example.com/2020/").NotContains(l.Target, "example.com/tag/")
+	}
+}
+
+func TestBuilder_related(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	src["conf.yaml"].Data = append(src["conf.yaml"].Data, []byte("\nrelated:\n  size: 2\n")...)
+	b := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+
+	data, err := fs.ReadFile(b.Dest, "posts/p1.html")
+	a.NotError(err).Contains(string(data), `<nav class="related">`)
+
+	// p3 与 p1 同属一个系列，排在 p2 之前。
+	html := string(data)
+	p3 := strings.Index(html, `<li><a href="https://example.com/posts/2020/12/p3.html">p3</a></li>`)
+	p2 := strings.Index(html, `<li><a href="https://example.com/posts/2020/p2.html">p2</a></li>`)
+	a.True(p3 > 0 && p2 > p3)
+}
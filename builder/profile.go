@@ -34,5 +34,5 @@ func (b *Builder) buildProfile(d *data.Data) error {
 		return buf.Err
 	}
 
-	return b.appendFile(p.Path, buf.Bytes())
+	return b.appendFile(p.Path, source{typ: SourceGenerated, name: PhaseProfile}, buf.Bytes())
 }
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+
+	"github.com/issue9/errwrap"
+
+	"github.com/caixw/blogit/v2/internal/data"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// 跳转页面的模板
+//
+// 不依赖于主题，除了 meta refresh 之外，还通过 canonical 告知搜索引擎新的地址。
+var redirectTemplate = template.Must(template.New("redirect").Parse(`<!DOCTYPE html>
+<html lang="{{.Post.Language}}">
+<head>
+<meta charset="utf-8" />
+<title>{{.Title}}</title>
+<meta name="robots" content="noindex" />
+<link rel="canonical" href="{{.Permalink}}" />
+<meta http-equiv="refresh" content="0; url={{.Permalink}}" />
+</head>
+<body>
+<a href="{{.Permalink}}">{{.Title}}</a>
+</body>
+</html>
+`))
+
+func (b *Builder) buildRedirects(d *data.Data) error {
+	redirects := make(map[string]string, len(d.Redirects))
+	for _, r := range d.Redirects {
+		buf := &bytes.Buffer{}
+		if err := redirectTemplate.Execute(buf, r); err != nil {
+			return err
+		}
+		if err := b.appendFile(r.Path, source{typ: SourceGenerated, name: PhaseRedirects}, buf.Bytes()); err != nil {
+			return err
+		}
+		redirects[r.Path] = r.Permalink
+	}
+	b.stageRedirects = redirects
+
+	if d.RedirectRules == nil {
+		return nil
+	}
+
+	if d.RedirectRules.Netlify {
+		err := b.buildRedirectRules(d, vars.RedirectsFilename, func(buf *errwrap.Buffer, from, to string) {
+			buf.Println(from, to, http.StatusMovedPermanently)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.RedirectRules.Nginx {
+		err := b.buildRedirectRules(d, vars.RedirectsMap, func(buf *errwrap.Buffer, from, to string) {
+			buf.Printf("%s %s;", strconv.Quote(from), strconv.Quote(to)).WByte('\n')
+			if dir, found := strings.CutSuffix(from, "/"); found { // $uri 中的目录可能不带 /
+				buf.Printf("%s %s;", strconv.Quote(dir), strconv.Quote(to)).WByte('\n')
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 生成重定向规则文件
+//
+// rule 用于输出单条规则，from 为旧地址的路径，包含了 URL 中的路径部分，to 为跳转的目标地址。
+func (b *Builder) buildRedirectRules(d *data.Data, path string, rule func(buf *errwrap.Buffer, from, to string)) error {
+	buf := &errwrap.Buffer{}
+	buf.Printf("# %s", vars.FileHeader).WByte('\n').WByte('\n')
+
+	for _, r := range d.Redirects {
+		u, err := neturl.Parse(r.URL)
+		if err != nil {
+			return err
+		}
+		rule(buf, u.EscapedPath(), r.Permalink)
+	}
+
+	if buf.Err != nil {
+		return buf.Err
+	}
+	return b.appendFile(path, source{typ: SourceGenerated, name: PhaseRedirects}, buf.Bytes())
+}
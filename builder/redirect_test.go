@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestBuilder_redirects(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	src["conf.yaml"].Data = append(src["conf.yaml"].Data, []byte(`
+redirects:
+  netlify: true
+  nginx: true
+`)...)
+	src["posts/alias.md"] = &fstest.MapFile{Data: []byte("---\ntitle: alias\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\naliases: [/2021/alias.html, /old/alias/]\n---\ncontent\n")}
+	b := &Builder{Src: src, Dest: MemoryFS(), CheckLinks: true}
+	a.NotError(b.Rebuild())
+
+	read := func(p string) string {
+		data, err := fs.ReadFile(b.Dest, p)
+		a.NotError(err)
+		return string(data)
+	}
+
+	page := read("2021/alias.html")
+	a.Contains(page, `<meta http-equiv="refresh" content="0; url=https://example.com/posts/alias.html" />`).
+		Contains(page, `<link rel="canonical" href="https://example.com/posts/alias.html" />`)
+	a.FileExistsFS(b.Dest, "old/alias/index.html").
+		NotContains(read(vars.SitemapXML), "2021/alias.html")
+
+	a.Contains(read(vars.RedirectsFilename), "/2021/alias.html https://example.com/posts/alias.html 301\n").
+		Contains(read(vars.RedirectsFilename), "/old/alias/ https://example.com/posts/alias.html 301\n")
+	a.Contains(read(vars.RedirectsMap), `"/old/alias/" "https://example.com/posts/alias.html";`).
+		Contains(read(vars.RedirectsMap), `"/old/alias" "https://example.com/posts/alias.html";`)
+
+	h := b.Handler(log.Default())
+	get := func(p string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, p, nil))
+		return w
+	}
+
+	w := get("/2021/alias.html")
+	a.Equal(w.Code, http.StatusMovedPermanently).
+		Equal(w.Header().Get("Location"), "https://example.com/posts/alias.html")
+	w = get("/old/alias")
+	a.Equal(w.Code, http.StatusMovedPermanently).
+		Equal(w.Header().Get("Location"), "https://example.com/posts/alias.html")
+	a.Equal(get("/posts/alias.html").Code, http.StatusOK)
+}
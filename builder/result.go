@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 在 BuildResult.Pages 中保留的页面数量
+const slowestPagesSize = 10
+
+// BuildResult 编译的统计报告
+type BuildResult struct {
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"` // 整个编译过程的耗时
+
+	Posts   int `json:"posts"`   // 文章的数量
+	Tags    int `json:"tags"`    // 标签的数量
+	Indexes int `json:"indexes"` // 索引页的数量
+	Statics int `json:"statics"` // 静态文件的数量
+
+	Files   int   `json:"files"`   // 写入 Builder.Dest 的文件数量
+	Removed int   `json:"removed"` // 从 Builder.Dest 删除的文件数量
+	Bytes   int64 `json:"bytes"`   // 写入 Builder.Dest 的字节数
+
+	// 下一次有文章发布或是过期的时间
+	//
+	// 零值表示没有，预览模式下始终为零值。
+	Scheduled time.Time `json:"scheduled"`
+
+	// 各个阶段的耗时
+	//
+	// 按执行的先后顺序排列，子阶段排在其所属的阶段之前。
+	Phases []*PhaseResult `json:"phases"`
+
+	// 渲染最慢的页面
+	//
+	// 按耗时从大到小排列，最多保留 10 条记录。
+	Pages []*PageResult `json:"pages"`
+
+	// 各个模板的渲染耗时
+	//
+	// 按总耗时从大到小排列。
+	Templates []*TemplateResult `json:"templates"`
+
+	// 链接检测的结果
+	//
+	// 仅在 Builder.CheckLinks 为 true 时才有值。
+	Links *LinkReport `json:"links,omitempty"`
+
+	mux       sync.Mutex
+	templates map[string]*TemplateResult
+}
+
+// PhaseResult 编译阶段的统计信息
+type PhaseResult struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// PageResult 页面的渲染信息
+type PageResult struct {
+	Path     string        `json:"path"`
+	Template string        `json:"template"`
+	Duration time.Duration `json:"duration"`
+}
+
+// TemplateResult 模板的渲染信息
+type TemplateResult struct {
+	Name     string        `json:"name"`
+	Count    int           `json:"count"`    // 渲染的次数
+	Duration time.Duration `json:"duration"` // 总耗时
+}
+
+func newResult() *BuildResult {
+	return &BuildResult{
+		Start:     time.Now(),
+		Phases:    make([]*PhaseResult, 0, 20),
+		Pages:     make([]*PageResult, 0, slowestPagesSize+1),
+		Templates: make([]*TemplateResult, 0, 10),
+		templates: make(map[string]*TemplateResult, 10),
+	}
+}
+
+// 记录页面的渲染耗时
+func (r *BuildResult) page(path, tpl string, dur time.Duration) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	t, found := r.templates[tpl]
+	if !found {
+		t = &TemplateResult{Name: tpl}
+		r.templates[tpl] = t
+		r.Templates = append(r.Templates, t)
+	}
+	t.Count++
+	t.Duration += dur
+
+	if len(r.Pages) == slowestPagesSize && r.Pages[slowestPagesSize-1].Duration >= dur {
+		return
+	}
+	r.Pages = append(r.Pages, &PageResult{Path: path, Template: tpl, Duration: dur})
+	sort.SliceStable(r.Pages, func(i, j int) bool { return r.Pages[i].Duration > r.Pages[j].Duration })
+	if len(r.Pages) > slowestPagesSize {
+		r.Pages = r.Pages[:slowestPagesSize]
+	}
+}
+
+func (r *BuildResult) end() {
+	r.Duration = time.Since(r.Start)
+	sort.SliceStable(r.Templates, func(i, j int) bool { return r.Templates[i].Duration > r.Templates[j].Duration })
+}
+
+// Result 最后一次编译的统计报告
+//
+// 编译失败时也会返回相应的报告，但是其中的内容可能是不完整的。
+// 如果从未编译过，返回 nil。
+func (b *Builder) Result() *BuildResult {
+	return b.result.Load()
+}
@@ -44,5 +44,5 @@ func (b *Builder) buildRobots(d *data.Data) error {
 		return buf.Err
 	}
 
-	return b.appendFile(d.Robots.Path, buf.Bytes())
+	return b.appendFile(d.Robots.Path, source{typ: SourceGenerated, name: PhaseRobots}, buf.Bytes())
 }
@@ -64,5 +64,5 @@ func (b *Builder) buildRSS(d *data.Data) error {
 		})
 	}
 
-	return b.appendXMLFile(d.RSS.Path, d.RSS.XSLPermalink, r)
+	return b.appendXMLFile(d.RSS.Path, d.RSS.XSLPermalink, PhaseRSS, r)
 }
@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"github.com/caixw/blogit/v2/internal/data"
+	"github.com/caixw/blogit/v2/internal/pool"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func (b *Builder) buildSeries(d *data.Data) error {
+	return pool.Run(b.ctx, b.Concurrency, len(d.Series), func(i int) error {
+		s := d.Series[i]
+		p := b.page(d, vars.SeriesTemplate)
+		p.Title = s.Title + d.TitleSuffix
+		p.Permalink = s.Permalink
+		p.Keywords = s.Keywords
+		p.Description = s.Content
+		p.Language = d.Language
+		p.Series = s
+		return b.appendTemplateFile(s.Path, p)
+	})
+}
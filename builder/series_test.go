@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestBuilder_buildSeries(t *testing.T) {
+	a := assert.New(t, false)
+	b := &Builder{Src: testdata.MapFS(t), Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+
+	read := func(p string) string {
+		data, err := fs.ReadFile(b.Dest, p)
+		a.NotError(err)
+		return string(data)
+	}
+
+	// 按系列中的顺序排列
+	a.Contains(read("series/example"+vars.Ext), `<ol class="series"><li><a href="https://example.com/posts/2020/12/p3.html">p3</a></li><li><a href="https://example.com/posts/p1.html">p1</a></li></ol>`).
+		Contains(read(vars.SitemapXML), "https://example.com/series/example.html").
+		Contains(read("posts/p1"+vars.Ext), "第 2 篇，共 2 篇").
+		Contains(read("posts/p1"+vars.Ext), `<a class="prev" href="https://example.com/posts/2020/12/p3.html">上一篇：p3</a></nav>`).
+		Contains(read("posts/2020/12/p3"+vars.Ext), "第 1 篇，共 2 篇").
+		Contains(read("posts/2020/12/p3"+vars.Ext), `</p><a class="next" href="https://example.com/posts/p1.html">下一篇：p1</a></nav>`)
+}
@@ -34,11 +34,16 @@ type page struct {
 	License     *loader.Link
 	Language    string
 	JSONLD      string // JSON-LD 数据
+	NoIndex     bool   // 是否禁止搜索引擎收录当前页面
+
+	// 当前页面在其它语言中的版本，不包含当前页面，可用于生成 hreflang 以及语言切换的链接。
+	Translations []*translation
 
 	// 以下内容，仅在对应的页面才会有内容
-	Tag      *data.Tag   // 标签详细页面，非标签详细页，则为空
-	Post     *data.Post  // 文章详细内容，仅文章页面用到。
-	Index    *data.Index // 索引页的数据
+	Tag      *data.Tag    // 标签详细页面，非标签详细页，则为空
+	Series   *data.Series // 系列的概览页面
+	Post     *data.Post   // 文章详细内容，仅文章页面用到。
+	Index    *data.Index  // 索引页的数据
 	Archives *data.Archives
 }
 
@@ -59,16 +64,34 @@ type site struct {
 	Sitemap  *loader.Link
 	Menus    []*loader.Link
 	Tags     *data.Tags
+	Series   []*data.Series
+	Language string
+
+	// 多语言站点中所有语言的首页，包括当前语言，非多语言站点为空。
+	Languages []*translation
 
 	Uptime   time.Time
 	Created  time.Time
 	Modified time.Time
 	Builded  time.Time // 最后次编译时间
+
+	// 所有文章的字数以及阅读时间的总和
+	WordCount   int
+	CharCount   int
+	ReadingTime int
+}
+
+// 页面在某一语言中的版本
+type translation struct {
+	Language  string
+	Title     string
+	Permalink string
 }
 
 type styleLink struct {
-	Media string
-	URL   string
+	Media     string
+	URL       string
+	Integrity string // 子资源完整性校验值，仅在开启了资源指纹时才有值。
 }
 
 func newSite(d *data.Data) *site {
@@ -86,11 +109,17 @@ func newSite(d *data.Data) *site {
 		Icon:     d.Icon,
 		Author:   d.Author,
 		Tags:     d.Tags,
+		Series:   d.Series,
+		Language: d.Language,
 
 		Uptime:   d.Uptime,
 		Created:  d.Created,
 		Modified: d.Modified,
 		Builded:  d.Builded,
+
+		WordCount:   d.WordCount,
+		CharCount:   d.CharCount,
+		ReadingTime: d.ReadingTime,
 	}
 
 	if d.RSS != nil {
@@ -110,16 +139,64 @@ func newSite(d *data.Data) *site {
 		})
 	}
 
+	for _, l := range d.Languages {
+		s.Languages = append(s.Languages, &translation{Language: l.Language, Title: l.Title, Permalink: l.URL})
+	}
+
 	return s
 }
 
-func (b *Builder) page(t string) *page {
+// 为每一种语言生成 site 对象，以语言 ID 为键名。
+//
+// 各语言共用同一组 Highlights，以便在生成资源指纹之后统一修改。
+func newSites(d *data.Data) map[string]*site {
+	main := newSite(d)
+	sites := map[string]*site{d.Language: main}
+	for _, l := range d.Languages {
+		if l == d {
+			continue
+		}
+
+		s := newSite(l)
+		s.Highlights = main.Highlights
+		s.Series = main.Series
+		sites[l.Language] = s
+	}
+	return sites
+}
+
+// 声明 d 所表示的语言中的页面
+func (b *Builder) page(d *data.Data, t string) *page {
 	return &page{
-		Site: b.site,
+		Site: b.sites[d.Language],
 		Type: t,
 	}
 }
 
+// 查找 d 以外的其它语言中与当前页面对应的页面
+//
+// f 用于从某一语言中获取对应页面的标题和链接，返回空的链接表示该语言中没有对应的页面。
+func translations(d *data.Data, f func(*data.Data) (title, permalink string)) []*translation {
+	ts := make([]*translation, 0, len(d.Languages))
+	for _, l := range d.Languages {
+		if l == d {
+			continue
+		}
+		if title, permalink := f(l); permalink != "" {
+			ts = append(ts, &translation{Language: l.Language, Title: title, Permalink: permalink})
+		}
+	}
+	return ts
+}
+
+func postTranslations(p *data.Post) []*translation {
+	ts := make([]*translation, 0, len(p.Translations))
+	for _, t := range p.Translations {
+		ts = append(ts, &translation{Language: t.Language, Title: t.Title, Permalink: t.Permalink})
+	}
+	return ts
+}
+
 var cssFormatter = html.New(
 	html.ClassPrefix(vars.HighlightClassPrefix),
 )
@@ -130,7 +207,7 @@ func (b *Builder) buildHighlights(d *data.Data) error {
 		if err := cssFormatter.WriteCSS(buf, styles.Get(h.Name)); err != nil {
 			return err
 		}
-		if err := b.appendFile(h.Path, buf.Bytes()); err != nil {
+		if err := b.appendFile(h.Path, source{typ: SourceGenerated, name: PhaseHighlights}, buf.Bytes()); err != nil {
 			return err
 		}
 	}
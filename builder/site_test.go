@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package builder
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/filesystem"
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestBuilder_languages(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	src["conf.yaml"].Data = append(src["conf.yaml"].Data, []byte("\nlanguages:\n  - id: en\n    title: English\n")...)
+	src["posts/zh.md"] = &fstest.MapFile{Data: []byte("---\ntitle: zh\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\ntranslationKey: hello\n---\n")}
+	src["posts/en.md"] = &fstest.MapFile{Data: []byte("---\ntitle: en\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\nlanguage: en\ntranslationKey: hello\n---\n")}
+	b := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+
+	read := func(p string) string {
+		data, err := fs.ReadFile(b.Dest, p)
+		a.NotError(err)
+		return string(data)
+	}
+
+	for _, p := range []string{"en/index.html", "en/tags.html", "en/tags/api.html", "en/archive.html", "en/rss.xml", "en/atom.xml"} {
+		a.True(filesystem.Exists(b.Dest, p), p)
+	}
+
+	a.Contains(read("posts/zh.html"), `<link rel="alternate" hreflang="en" href="https://example.com/posts/en.html" />`).
+		Contains(read("posts/en.html"), `<html lang="en">`).
+		Contains(read("posts/en.html"), `<link rel="alternate" hreflang="cmn-Hans" href="https://example.com/posts/zh.html" />`).
+		Contains(read("index.html"), `hreflang="en" href="https://example.com/en/"`).
+		Contains(read("en/tags/api.html"), `hreflang="cmn-Hans" href="https://example.com/tags/api.html"`).
+		NotContains(read("index.html"), "posts/en.html").
+		Contains(read("en/index.html"), "posts/en.html")
+
+	sitemap := read(vars.SitemapXML)
+	a.Contains(sitemap, `xmlns:xhtml="http://www.w3.org/1999/xhtml"`).
+		Contains(sitemap, "<loc>https://example.com/en/</loc>").
+		Contains(sitemap, `<xhtml:link rel="alternate" hreflang="en" href="https://example.com/posts/en.html"></xhtml:link>`)
+
+	a.Equal(b.Result().Posts, 5)
+}
+
+func TestBuilder_languages_unlisted(t *testing.T) {
+	a := assert.New(t, false)
+	src := testdata.MapFS(t)
+	src["conf.yaml"].Data = append(src["conf.yaml"].Data, []byte("\nlanguages:\n  - id: en\n")...)
+	src["posts/zh.md"] = &fstest.MapFile{Data: []byte("---\ntitle: zh\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\ntranslationKey: hello\n---\n")}
+	src["posts/en.md"] = &fstest.MapFile{Data: []byte("---\ntitle: en\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\nlanguage: en\nstate: unlisted\ntranslationKey: hello\n---\n")}
+	b := &Builder{Src: src, Dest: MemoryFS()}
+	a.NotError(b.Rebuild())
+
+	read := func(p string) string {
+		data, err := fs.ReadFile(b.Dest, p)
+		a.NotError(err)
+		return string(data)
+	}
+
+	a.NotContains(read("posts/zh.html"), "https://example.com/posts/en.html").
+		NotContains(read("posts/zh.html"), `hreflang="en"`).
+		NotContains(read(vars.SitemapXML), "https://example.com/posts/en.html")
+}
@@ -11,19 +11,31 @@ import (
 	"github.com/caixw/blogit/v2/internal/data"
 )
 
-const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+const (
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	xhtmlNamespace   = "http://www.w3.org/1999/xhtml"
+)
 
 type urlset struct {
 	XMLName struct{} `xml:"urlset"`
 	XMLNS   string   `xml:"xmlns,attr"`
+	XHTML   string   `xml:"xmlns:xhtml,attr,omitempty"` // 多语言站点才有
 	URLSet  []*url   `xml:"url,omitempty"`
 }
 
 type url struct {
-	Loc        string `xml:"loc"`
-	Lastmod    string `xml:"lastmod"`
-	Changefreq string `xml:"changefreq"`
-	Priority   string `xml:"priority"`
+	Loc        string       `xml:"loc"`
+	Lastmod    string       `xml:"lastmod"`
+	Changefreq string       `xml:"changefreq"`
+	Priority   string       `xml:"priority"`
+	Links      []*xhtmlLink `xml:"xhtml:link,omitempty"`
+}
+
+// 页面在各语言中的版本
+type xhtmlLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
 }
 
 func (b *Builder) buildSitemap(d *data.Data) error {
@@ -33,30 +45,63 @@ func (b *Builder) buildSitemap(d *data.Data) error {
 
 	s := &urlset{
 		XMLNS:  sitemapNamespace,
-		URLSet: make([]*url, 0, len(d.Tags.Tags)+len(d.Posts)),
+		URLSet: make([]*url, 0, len(d.Tags.Tags)+len(d.Series)+len(d.Posts)+len(d.Pages)+2),
+	}
+	if len(d.Languages) > 0 {
+		s.XHTML = xhtmlNamespace
 	}
 
 	conf := d.Sitemap
-	if conf.EnableTag {
-		s.append(d.Tags.Permalink, d.Modified, conf.Changefreq, conf.Priority)
-		for _, tag := range d.Tags.Tags {
-			s.append(tag.Permalink, tag.Modified, conf.Changefreq, conf.Priority)
+	for _, l := range d.AllLanguages() {
+		if conf.EnableTag {
+			s.append(l.Tags.Permalink, l.Modified, conf.Changefreq, conf.Priority, alternates(l.Language, l.Tags.Permalink, tagsTranslations(l)))
+			for _, tag := range l.Tags.Tags {
+				s.append(tag.Permalink, tag.Modified, conf.Changefreq, conf.Priority, alternates(l.Language, tag.Permalink, tagTranslations(l, tag)))
+			}
 		}
+
+		s.append(l.URL, l.Modified, conf.Changefreq, conf.Priority, alternates(l.Language, l.URL, indexTranslations(l)))
+		for _, p := range l.Posts {
+			s.append(p.Permalink, p.Modified, conf.PostChangefreq, conf.PostPriority, alternates(p.Language, p.Permalink, postTranslations(p)))
+		}
+	}
+
+	for _, series := range d.Series {
+		s.append(series.Permalink, series.Modified, conf.Changefreq, conf.Priority, nil)
 	}
 
-	s.append(d.URL, d.Modified, conf.Changefreq, conf.Priority)
-	for _, p := range d.Posts {
-		s.append(p.Permalink, p.Modified, conf.PostChangefreq, conf.PostPriority)
+	for _, p := range d.Pages {
+		if p.Unlisted {
+			continue
+		}
+		s.append(p.Permalink, p.Modified, conf.PostChangefreq, conf.PostPriority, alternates(p.Language, p.Permalink, postTranslations(p)))
 	}
 
-	return b.appendXMLFile(conf.Path, conf.XSLPermalink, s)
+	return b.appendXMLFile(conf.Path, conf.XSLPermalink, PhaseSitemap, s)
 }
 
-func (us *urlset) append(loc string, lastmod time.Time, changefreq string, priority float64) {
+func (us *urlset) append(loc string, lastmod time.Time, changefreq string, priority float64, links []*xhtmlLink) {
 	us.URLSet = append(us.URLSet, &url{
 		Loc:        loc,
 		Lastmod:    lastmod.Format(time.RFC3339),
 		Changefreq: changefreq,
 		Priority:   strconv.FormatFloat(priority, 'f', 1, 32),
+		Links:      links,
 	})
 }
+
+// 生成页面在各语言中的版本，包括页面自身。
+//
+// 没有其它语言的版本时返回空值。
+func alternates(lang, permalink string, ts []*translation) []*xhtmlLink {
+	if len(ts) == 0 {
+		return nil
+	}
+
+	links := make([]*xhtmlLink, 0, len(ts)+1)
+	links = append(links, &xhtmlLink{Rel: "alternate", Hreflang: lang, Href: permalink})
+	for _, t := range ts {
+		links = append(links, &xhtmlLink{Rel: "alternate", Hreflang: t.Language, Href: t.Permalink})
+	}
+	return links
+}
@@ -7,18 +7,21 @@ package builder
 import (
 	"github.com/caixw/blogit/v2/internal/data"
 	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/pool"
 	"github.com/caixw/blogit/v2/internal/vars"
 )
 
 func (b *Builder) buildTags(d *data.Data) error {
-	for _, t := range d.Tags.Tags {
-		p := b.page(vars.TagTemplate)
+	err := pool.Run(b.ctx, b.Concurrency, len(d.Tags.Tags), func(i int) error {
+		t := d.Tags.Tags[i]
+		p := b.page(d, vars.TagTemplate)
 		p.Title = t.Title + d.TitleSuffix
 		p.Permalink = t.Permalink
 		p.Keywords = t.Keywords
 		p.Description = t.Content
 		p.Language = d.Language
 		p.Tag = t
+		p.Translations = tagTranslations(d, t)
 
 		if t.Next != nil {
 			p.Next = &loader.Link{
@@ -33,16 +36,35 @@ func (b *Builder) buildTags(d *data.Data) error {
 			}
 		}
 
-		if err := b.appendTemplateFile(t.Path, p); err != nil {
-			return err
-		}
+		return b.appendTemplateFile(t.Path, p)
+	})
+	if err != nil {
+		return err
 	}
 
-	p := b.page(vars.TagsTemplate)
+	p := b.page(d, vars.TagsTemplate)
 	p.Title = d.Tags.Title + d.TitleSuffix
 	p.Permalink = d.Tags.Permalink
 	p.Keywords = d.Tags.Keywords
 	p.Description = d.Tags.Description
 	p.Language = d.Language
-	return b.appendTemplateFile(vars.TagsFilename, p)
+	p.Translations = tagsTranslations(d)
+	return b.appendTemplateFile(d.Tags.Path, p)
+}
+
+// 其它语言中的标签列表
+func tagsTranslations(d *data.Data) []*translation {
+	return translations(d, func(l *data.Data) (string, string) { return l.Tags.Title, l.Tags.Permalink })
+}
+
+// 其它语言中与 t 拥有相同 slug 的标签
+func tagTranslations(d *data.Data, t *data.Tag) []*translation {
+	return translations(d, func(l *data.Data) (string, string) {
+		for _, lt := range l.Tags.Tags {
+			if lt.Slug == t.Slug {
+				return lt.Title, lt.Permalink
+			}
+		}
+		return "", ""
+	})
 }
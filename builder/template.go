@@ -20,22 +20,42 @@ import (
 func (b *Builder) appendTemplateFile(path string, p *page) error {
 	buf := &bytes.Buffer{}
 
+	start := time.Now()
 	if err := b.tpl.ExecuteTemplate(buf, p.Type, p); err != nil {
 		return err
 	}
+	b.current.page(path, p.Type, time.Since(start))
 
-	return b.appendFile(path, buf.Bytes())
+	src := source{typ: SourceTemplate, name: p.Type}
+	if p.Post != nil {
+		src = source{typ: SourcePost, name: p.Post.Slug}
+	}
+	return b.appendFile(path, src, buf.Bytes())
 }
 
-func newTemplate(d *data.Data, src fs.FS) (*template.Template, error) {
+func newTemplate(d *data.Data, src fs.FS, fp *fingerprints) (*template.Template, error) {
+	themeURL := func(p string) string {
+		return data.BuildURL(d.URL, vars.ThemesDir, p)
+	}
+
 	templateFuncs := template.FuncMap{
-		"strip":   stripTags,
-		"html":    func(html string) interface{} { return template.HTML(html) },
-		"js":      func(js string) interface{} { return template.JS(js) },
-		"rfc3339": func(t time.Time) string { return t.Format(time.RFC3339) },
-		"date":    func(t time.Time, format string) string { return t.Format(format) },
-		"themeURL": func(p string) string {
-			return data.BuildURL(d.URL, vars.ThemesDir, p)
+		"strip":    stripTags,
+		"html":     func(html string) interface{} { return template.HTML(html) },
+		"js":       func(js string) interface{} { return template.JS(js) },
+		"rfc3339":  func(t time.Time) string { return t.Format(time.RFC3339) },
+		"date":     func(t time.Time, format string) string { return t.Format(format) },
+		"themeURL": themeURL,
+		"assetURL": func(p string) string {
+			if a := fp.get(p); a != nil {
+				return a.URL
+			}
+			return themeURL(p)
+		},
+		"integrity": func(p string) string {
+			if a := fp.get(p); a != nil {
+				return a.Integrity
+			}
+			return ""
 		},
 	}
 
@@ -8,6 +8,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/caixw/blogit/v2/internal/cmd"
@@ -15,6 +16,7 @@ import (
 
 func main() {
 	if err := cmd.Exec(os.Args[1:]); err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
@@ -5,9 +5,18 @@
 package cmd
 
 import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/issue9/cmdopt"
@@ -15,13 +24,27 @@ import (
 	"golang.org/x/text/message"
 
 	"github.com/caixw/blogit/v2"
+	"github.com/caixw/blogit/v2/internal/vars"
 )
 
 const (
-	buildTitle     = localeutil.StringPhrase("build title")
-	buildUsage     = localeutil.StringPhrase("build usage")
-	buildSrcUsage  = localeutil.StringPhrase("build src")
-	buildDestUsage = localeutil.StringPhrase("build dest")
+	buildTitle            = localeutil.StringPhrase("build title")
+	buildUsage            = localeutil.StringPhrase("build usage")
+	buildSrcUsage         = localeutil.StringPhrase("build src")
+	buildDestUsage        = localeutil.StringPhrase("build dest")
+	buildCacheUsage       = localeutil.StringPhrase("build cache")
+	buildConcurrencyUsage = localeutil.StringPhrase("build concurrency")
+	buildReportUsage      = localeutil.StringPhrase("build report")
+	buildManifestUsage    = localeutil.StringPhrase("build manifest")
+	buildDryRunUsage      = localeutil.StringPhrase("build dry-run")
+	buildDiffUsage        = localeutil.StringPhrase("build diff")
+)
+
+// build -report 的可选值
+const (
+	reportNone = ""
+	reportText = "text"
+	reportJSON = "json"
 )
 
 // initBuild 注册 build 子命令
@@ -29,14 +52,65 @@ func initBuild(opt *cmdopt.CmdOpt, p *message.Printer) {
 	opt.New("build", buildTitle.LocaleString(p), buildUsage.LocaleString(p), func(fs *flag.FlagSet) cmdopt.DoFunc {
 		var buildSrc string
 		var buildDest string
+		var buildCache string
+		var buildConcurrency int
+		var buildReport string
+		var buildManifest bool
+		var buildDryRun bool
+		var buildDiff bool
 		fs.StringVar(&buildSrc, "src", "./", buildSrcUsage.LocaleString(p))
 		fs.StringVar(&buildDest, "dest", "./dest", buildDestUsage.LocaleString(p))
+		fs.StringVar(&buildCache, "cache", "", buildCacheUsage.LocaleString(p))
+		fs.IntVar(&buildConcurrency, "concurrency", runtime.NumCPU(), buildConcurrencyUsage.LocaleString(p))
+		fs.StringVar(&buildReport, "report", reportNone, buildReportUsage.LocaleString(p))
+		fs.BoolVar(&buildManifest, "manifest", false, buildManifestUsage.LocaleString(p))
+		fs.BoolVar(&buildDryRun, "dry-run", false, buildDryRunUsage.LocaleString(p))
+		fs.BoolVar(&buildDiff, "diff", false, buildDiffUsage.LocaleString(p))
 
 		return func(w io.Writer) error {
+			if buildReport != reportNone && buildReport != reportText && buildReport != reportJSON {
+				erro.Println(localeutil.Phrase("invalid report format %s", buildReport).LocaleString(p))
+				return nil
+			}
+
 			start := time.Now()
 
-			info.Println(localeutil.StringPhrase("start build").LocaleString(p))
-			if err := blogit.Build(os.DirFS(buildSrc), blogit.DirFS(buildDest), info.AsLogger()); err != nil {
+			var dest blogit.WritableFS
+			finish := func(bool) error { return nil }
+			if buildDryRun {
+				dest = blogit.MemoryFS()
+				buildCache = "" // 不能修改缓存文件
+			} else {
+				var err error
+				if dest, finish, err = newBuildDest(buildDest); err != nil {
+					return err
+				}
+			}
+
+			b := &blogit.Builder{
+				Src:         os.DirFS(buildSrc),
+				Dest:        dest,
+				Incremental: buildCache != "",
+				CacheFile:   buildCache,
+				Concurrency: buildConcurrency,
+			}
+			if buildManifest {
+				b.ManifestFile = vars.ManifestJSON
+			}
+			if buildReport != reportJSON { // JSON 格式只输出报告的内容
+				info.Println(localeutil.StringPhrase("start build").LocaleString(p))
+				if !buildDryRun {
+					b.Info = info.AsLogger()
+				}
+			}
+			err := b.Rebuild()
+			if err == nil && buildDryRun {
+				err = printDryRun(w, p, buildDest, dest, buildDiff)
+			}
+			if err1 := finish(err == nil); err == nil {
+				err = err1
+			}
+			if err != nil {
 				if ls, ok := err.(localeutil.Stringer); ok {
 					erro.Println(ls.LocaleString(p))
 				} else {
@@ -45,8 +119,142 @@ func initBuild(opt *cmdopt.CmdOpt, p *message.Printer) {
 				return nil
 			}
 
+			switch buildReport {
+			case reportJSON:
+				e := json.NewEncoder(w)
+				e.SetIndent("", "\t")
+				return e.Encode(b.Result())
+			case reportText:
+				if err := printReport(w, p, b.Result()); err != nil {
+					return err
+				}
+			}
+
 			succ.Println(localeutil.StringPhrase("build complete").LocaleString(p), time.Since(start))
 			return nil
 		}
 	})
 }
+
+// 根据 dest 的扩展名返回相应的 WritableFS
+//
+// 以 .zip、.tar.gz 和 .tgz 结尾的，输出为相应格式的归档文件，其它的则作为目录。
+// finish 用于在编译结束之后完成写入，ok 表示编译是否成功，
+// 编译失败时不会修改已经存在的归档文件。
+func newBuildDest(dest string) (wfs blogit.WritableFS, finish func(ok bool) error, err error) {
+	var newFS func(io.Writer) blogit.ArchiveFS
+	switch lower := strings.ToLower(dest); {
+	case strings.HasSuffix(lower, ".zip"):
+		newFS = blogit.ZipFS
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		newFS = blogit.TarGzFS
+	default:
+		return blogit.DirFS(dest), func(bool) error { return nil }, nil
+	}
+
+	// 先写入临时文件，成功之后再重命名。
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return nil, nil, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afs := newFS(f)
+	return afs, func(ok bool) (err error) {
+		if ok {
+			err = afs.Close()
+		}
+		if err1 := f.Close(); err == nil {
+			err = err1
+		}
+		if ok && err == nil {
+			err = os.Chmod(f.Name(), 0o644) // os.CreateTemp 创建的文件仅对当前用户可读
+		}
+		if ok && err == nil {
+			return os.Rename(f.Name(), dest)
+		}
+
+		if err1 := os.Remove(f.Name()); err == nil {
+			err = err1
+		}
+		return err
+	}, nil
+}
+
+// 输出 curr 相对于 dest 的变化
+func printDryRun(w io.Writer, p *message.Printer, dest string, curr fs.FS, unified bool) error {
+	var destFS fs.FS
+	switch lower := strings.ToLower(dest); {
+	case strings.HasSuffix(lower, ".zip"):
+		r, err := zip.OpenReader(dest)
+		if errors.Is(err, fs.ErrNotExist) {
+			destFS = blogit.MemoryFS()
+			break
+		} else if err != nil {
+			return err
+		}
+		defer r.Close()
+		destFS = r
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		return localeutil.Error("dry-run not support %s", dest)
+	default:
+		destFS = blogit.DirFS(dest)
+	}
+
+	changes, err := blogit.Diff(destFS, curr, unified)
+	if err != nil {
+		return err
+	}
+
+	var created, modified, deleted int
+	for _, c := range changes {
+		var flag byte
+		switch c.Type {
+		case blogit.ChangeCreate:
+			flag = '+'
+			created++
+		case blogit.ChangeModify:
+			flag = '~'
+			modified++
+		case blogit.ChangeDelete:
+			flag = '-'
+			deleted++
+		}
+		if _, err := fmt.Fprintf(w, "%c %s\n", flag, c.Path); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, c.Diff); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(w, localeutil.Phrase("dry-run summary %d %d %d", created, modified, deleted).LocaleString(p))
+	return err
+}
+
+// 以文本的形式输出编译报告
+func printReport(w io.Writer, p *message.Printer, r *blogit.BuildResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, localeutil.Phrase("report counts %d %d %d %d", r.Posts, r.Tags, r.Indexes, r.Statics).LocaleString(p))
+	fmt.Fprintln(tw, localeutil.Phrase("report outputs %d %d %d", r.Files, r.Removed, r.Bytes).LocaleString(p))
+
+	fmt.Fprintln(tw, localeutil.StringPhrase("report phases").LocaleString(p))
+	for _, phase := range r.Phases {
+		fmt.Fprintf(tw, "\t%s\t%s\n", phase.Name, phase.Duration)
+	}
+
+	fmt.Fprintln(tw, localeutil.StringPhrase("report pages").LocaleString(p))
+	for _, page := range r.Pages {
+		fmt.Fprintf(tw, "\t%s\t%s\t%s\n", page.Path, page.Template, page.Duration)
+	}
+
+	fmt.Fprintln(tw, localeutil.StringPhrase("report templates").LocaleString(p))
+	for _, t := range r.Templates {
+		fmt.Fprintf(tw, "\t%s\t%d\t%s\n", t.Name, t.Count, t.Duration)
+	}
+
+	return tw.Flush()
+}
@@ -5,11 +5,15 @@
 package cmd
 
 import (
+	"archive/zip"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/issue9/assert/v4"
 
+	"github.com/caixw/blogit/v2"
 	"github.com/caixw/blogit/v2/internal/filesystem"
 	"github.com/caixw/blogit/v2/internal/vars"
 )
@@ -24,3 +28,84 @@ func TestCmd_Build(t *testing.T) {
 	fs := os.DirFS(dest)
 	a.True(filesystem.Exists(fs, "index"+vars.Ext))
 }
+
+func TestCmd_Build_report(t *testing.T) {
+	a := assert.New(t, false)
+	exec := func(report string) string {
+		opt, buf, p := newCMD(a)
+		initBuild(opt, p)
+		a.NotError(opt.Exec([]string{"build", "-src", "../testdata", "-dest", t.TempDir(), "-report", report}))
+		return buf.String()
+	}
+
+	r := &blogit.BuildResult{}
+	a.NotError(json.Unmarshal([]byte(exec("json")), r)).
+		Equal(r.Posts, 3).
+		NotEmpty(r.Phases)
+
+	out := exec("text")
+	a.Contains(out, "index.html").
+		Contains(out, "load.posts")
+
+	a.Empty(exec("xml"))
+}
+
+func TestCmd_Build_manifest(t *testing.T) {
+	a := assert.New(t, false)
+	dest := t.TempDir()
+
+	a.NotError(Exec([]string{"build", "-src", "../testdata", "-dest", dest, "-manifest"}))
+	a.FileExists(filepath.Join(dest, vars.ManifestJSON))
+}
+
+func TestCmd_Build_archive(t *testing.T) {
+	a := assert.New(t, false)
+	dir := t.TempDir()
+
+	zipFile := filepath.Join(dir, "site.zip")
+	a.NotError(Exec([]string{"build", "-src", "../testdata", "-dest", zipFile}))
+	r, err := zip.OpenReader(zipFile)
+	a.NotError(err)
+	a.True(filesystem.Exists(r, "index"+vars.Ext))
+	a.NotError(r.Close())
+
+	tgzFile := filepath.Join(dir, "out", "site.tar.gz")
+	a.NotError(Exec([]string{"build", "-src", "../testdata", "-dest", tgzFile}))
+	a.FileExists(tgzFile)
+
+	// 编译失败，不会生成文件。
+	failed := filepath.Join(dir, "failed.zip")
+	a.NotError(Exec([]string{"build", "-src", "./not-exists", "-dest", failed}))
+	a.FileNotExists(failed)
+	entries, err := os.ReadDir(dir)
+	a.NotError(err).Length(entries, 2) // site.zip 和 out，不包含临时文件。
+}
+
+func TestCmd_Build_dryRun(t *testing.T) {
+	a := assert.New(t, false)
+	dest := t.TempDir()
+
+	exec := func(args ...string) string {
+		opt, buf, p := newCMD(a)
+		initBuild(opt, p)
+		a.NotError(opt.Exec(append([]string{"build", "-src", "../testdata"}, args...)))
+		return buf.String()
+	}
+
+	out := exec("-dest", dest, "-dry-run")
+	a.Contains(out, "+ index"+vars.Ext).
+		NotContains(out, "@@")
+	a.FileNotExists(filepath.Join(dest, "index"+vars.Ext))
+
+	exec("-dest", dest)
+	a.FileExists(filepath.Join(dest, "index"+vars.Ext))
+
+	out = exec("-dest", dest, "-dry-run", "-diff")
+	a.NotContains(out, "+ index"+vars.Ext).
+		NotContains(out, "- index"+vars.Ext)
+
+	zipFile := filepath.Join(t.TempDir(), "site.zip")
+	out = exec("-dest", zipFile, "-dry-run")
+	a.Contains(out, "+ index"+vars.Ext).
+		FileNotExists(zipFile)
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/issue9/cmdopt"
+	"github.com/issue9/localeutil"
+	"golang.org/x/text/message"
+
+	"github.com/caixw/blogit/v2"
+)
+
+const (
+	checkTitle         = localeutil.StringPhrase("check title")
+	checkUsage         = localeutil.StringPhrase("check usage")
+	checkSrcUsage      = localeutil.StringPhrase("check src")
+	checkExternalUsage = localeutil.StringPhrase("check external")
+)
+
+// initCheck 注册 check 子命令
+//
+// 在内存中编译之后检测所有页面的站内链接，不会对输出目录产生影响。
+// 存在无效的链接时，命令会返回错误。
+func initCheck(opt *cmdopt.CmdOpt, p *message.Printer) {
+	opt.New("check", checkTitle.LocaleString(p), checkUsage.LocaleString(p), func(fs *flag.FlagSet) cmdopt.DoFunc {
+		var checkSrc string
+		var checkExternal bool
+		fs.StringVar(&checkSrc, "src", "./", checkSrcUsage.LocaleString(p))
+		fs.BoolVar(&checkExternal, "external", false, checkExternalUsage.LocaleString(p))
+
+		return func(w io.Writer) error {
+			b := &blogit.Builder{
+				Src:        os.DirFS(checkSrc),
+				Dest:       blogit.MemoryFS(),
+				CheckLinks: true,
+			}
+			if err := b.Rebuild(); err != nil {
+				return err
+			}
+
+			links := b.Result().Links
+			if err := printLinks(w, p, links, checkExternal); err != nil {
+				return err
+			}
+
+			// 存在无效链接时返回错误，以便在 CI 等环境中以非零状态退出。
+			if len(links.Broken) > 0 {
+				return localeutil.Error("broken links %d", len(links.Broken))
+			}
+			succ.Println(localeutil.StringPhrase("no broken links").LocaleString(p))
+			return nil
+		}
+	})
+}
+
+func printLinks(w io.Writer, p *message.Printer, links *blogit.LinkReport, external bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	for _, l := range links.Broken {
+		switch {
+		case l.Post == "":
+			fmt.Fprintf(tw, "%s\t%s\n", l.Page, l.Target)
+		case l.Line > 0:
+			fmt.Fprintf(tw, "%s:%d\t%s\n", l.Post, l.Line, l.Target)
+		default:
+			fmt.Fprintf(tw, "%s\t%s\n", l.Post, l.Target)
+		}
+	}
+
+	if external && len(links.External) > 0 {
+		fmt.Fprintln(tw, localeutil.StringPhrase("external links").LocaleString(p))
+		for _, l := range links.External {
+			fmt.Fprintf(tw, "\t%s\n", l)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestCheck(t *testing.T) {
+	a := assert.New(t, false)
+
+	opt, buf, p := newCMD(a)
+	initCheck(opt, p)
+	a.Error(opt.Exec([]string{"check", "-src", "../testdata"})) // 存在无效链接时返回错误
+	// testdata 中并不存在 favicon.png
+	a.Contains(buf.String(), "/favicon.png").
+		NotContains(buf.String(), "https://caixw.io")
+
+	opt, buf, p = newCMD(a)
+	initCheck(opt, p)
+	a.Error(opt.Exec([]string{"check", "-src", "../testdata", "-external"}))
+	a.Contains(buf.String(), "https://caixw.io")
+}
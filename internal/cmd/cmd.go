@@ -6,6 +6,7 @@
 package cmd
 
 import (
+	"errors"
 	"flag"
 	"os"
 
@@ -45,6 +46,8 @@ const (
 )
 
 // Exec 执行命令行
+//
+// 返回的错误已经按当前用户的语言进行了本地化。
 func Exec(args []string) error {
 	systag, _ := localeutil.DetectUserLanguageTag() // 即使出错，依然会返回 language.Tag
 	p, err := console.NewPrinter(systag)
@@ -58,6 +61,7 @@ func Exec(args []string) error {
 
 	initDrafts(opt, p)
 	initBuild(opt, p)
+	initCheck(opt, p)
 	initVersion(opt, p)
 	initStyles(opt, p)
 	serve.Init(opt, succ, info, erro, p)
@@ -66,5 +70,9 @@ func Exec(args []string) error {
 	create.InitPost(opt, succ, erro, p)
 	cmdopt.Help(opt, "help", helpUsage.LocaleString(p), helpUsage.LocaleString(p))
 
-	return opt.Exec(args)
+	err = opt.Exec(args)
+	if ls, ok := err.(localeutil.Stringer); ok {
+		return errors.New(ls.LocaleString(p))
+	}
+	return err
 }
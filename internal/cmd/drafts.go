@@ -5,6 +5,7 @@
 package cmd
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
@@ -31,7 +32,7 @@ func initDrafts(opt *cmdopt.CmdOpt, p *message.Printer) {
 		fs.StringVar(&draftsSrc, "src", "./", draftsSrcUsage.LocaleString(p))
 
 		return func(w io.Writer) error {
-			d, err := data.Load(os.DirFS(draftsSrc), true, "")
+			d, err := data.Load(context.Background(), os.DirFS(draftsSrc), &data.Options{Preview: true})
 			if err != nil {
 				return err
 			}
@@ -11,8 +11,8 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
-	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/issue9/localeutil"
@@ -100,7 +100,12 @@ func (o *options) parseURL() error {
 }
 
 func (o *options) build(erro *console.Logger) (ok bool) {
-	if err := o.b.Rebuild(); err != nil {
+	return o.printError(erro, o.b.Rebuild())
+}
+
+// 输出 err 的内容，返回值表示 err 是否为空。
+func (o *options) printError(erro *console.Logger, err error) (ok bool) {
+	if err != nil {
 		if ls, ok := err.(localeutil.Stringer); ok {
 			erro.Println(ls.LocaleString(o.p))
 		} else {
@@ -117,11 +122,13 @@ func (o *options) watch(succ, info, erro *console.Logger) error {
 	}
 
 	o.b = &blogit.Builder{
-		Src:     o.srcFS,
-		Dest:    o.destFS,
-		Info:    info.AsLogger(),
-		Preview: true,
-		BaseURL: o.url,
+		Src:         o.srcFS,
+		Dest:        o.destFS,
+		Info:        info.AsLogger(),
+		Preview:     true,
+		BaseURL:     o.url,
+		Incremental: true,
+		Concurrency: runtime.NumCPU(),
 	}
 
 	h := console.Visiter(o.b.Handler(erro.AsLogger()), o.p, succ, erro)
@@ -141,6 +148,18 @@ func (o *options) watch(succ, info, erro *console.Logger) error {
 		return err
 	}
 
+	// 编译期间触发的事件只会在 rebuild 中留下一个信号，编译完成之后再进行一次编译，
+	// 所以不会丢失任何事件，每一次编译也只输出一次结果。
+	rebuild := make(chan struct{}, 1)
+	go func() {
+		for range rebuild {
+			if o.printError(erro, <-o.b.RequestRebuild()) {
+				succ.Println(localeutil.Phrase("preview rebuild success %s").LocaleString(o.p))
+			}
+		}
+	}()
+	defer close(rebuild)
+
 	for {
 		select {
 		case event := <-watcher.Events:
@@ -148,19 +167,12 @@ func (o *options) watch(succ, info, erro *console.Logger) error {
 				continue
 			}
 
-			if time.Since(o.b.Builded()) <= time.Second {
-				info.Println(localeutil.Phrase("preview ignore %s", event).LocaleString(o.p))
-				continue
-			}
-
 			info.Println(localeutil.Phrase("preview trigger event %s", event).LocaleString(o.p))
 
-			go func() {
-				if !o.build(erro) {
-					return
-				}
-				succ.Println(localeutil.Phrase("preview rebuild success %s").LocaleString(o.p))
-			}()
+			select {
+			case rebuild <- struct{}{}:
+			default: // 已经有等待中的编译
+			}
 		case err := <-watcher.Errors:
 			erro.Println(err)
 			return err
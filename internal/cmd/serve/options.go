@@ -7,6 +7,7 @@ package serve
 import (
 	"net/http"
 	"os"
+	"runtime"
 
 	"github.com/issue9/localeutil"
 	"golang.org/x/text/message"
@@ -41,14 +42,16 @@ type options struct {
 	hookURL    string
 	hookAuth   string
 
-	b   *blogit.Builder
-	srv *http.Server
+	b    *blogit.Builder
+	srv  *http.Server
+	erro *console.Logger
 }
 
 func (o *options) serve(succ, info, erro *console.Logger) error {
 	if err := o.sanitize(); err != nil {
 		return err
 	}
+	o.erro = erro
 
 	var dest blogit.WritableFS
 	if o.dest == "" {
@@ -59,9 +62,12 @@ func (o *options) serve(succ, info, erro *console.Logger) error {
 	src := os.DirFS(o.source)
 
 	o.b = &blogit.Builder{
-		Src:  src,
-		Dest: dest,
-		Info: info.AsLogger(),
+		Src:         src,
+		Dest:        dest,
+		Info:        info.AsLogger(),
+		Incremental: true,
+		Concurrency: runtime.NumCPU(),
+		AutoPublish: true,
 	}
 	if err := o.b.Rebuild(); err != nil {
 		return err
@@ -92,7 +98,18 @@ func (o *options) webhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	o.b.Rebuild()
+	// 不等待编译完成，编译结果输出到日志，期间的多次调用会被合并为一次编译。
+	ch := o.b.RequestRebuild()
+	go func() {
+		if err := <-ch; err != nil {
+			if ls, ok := err.(localeutil.Stringer); ok {
+				o.erro.Println(ls.LocaleString(o.p))
+			} else {
+				o.erro.Println(err)
+			}
+		}
+	}()
+	w.WriteHeader(http.StatusAccepted)
 }
 
 func (o *options) sanitize() error {
@@ -53,9 +53,12 @@ func TestOptions_serve(t *testing.T) {
 	a.NotError(err).NotNil(p)
 
 	o := &options{
-		p:      p,
-		source: "../../testdata",
-		addr:   ":8081",
+		p:          p,
+		source:     "../../testdata",
+		addr:       ":8081",
+		hookURL:    "/hook",
+		hookMethod: http.MethodPost,
+		hookAuth:   "auth",
 	}
 
 	succ := &console.Logger{Colorize: colors.New(os.Stdout)}
@@ -85,6 +88,16 @@ func TestOptions_serve(t *testing.T) {
 	resp, err = http.Get("http://localhost:8081/not-exists.html")
 	a.NotError(err).NotNil(resp).Equal(resp.StatusCode, http.StatusNotFound)
 
+	// webhook
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8081/hook", nil)
+	a.NotError(err).NotNil(req)
+	req.Header.Set("Authorization", "auth")
+	resp, err = http.DefaultClient.Do(req)
+	a.NotError(err).NotNil(resp).Equal(resp.StatusCode, http.StatusAccepted)
+
+	resp, err = http.Get("http://localhost:8081/hook")
+	a.NotError(err).NotNil(resp).Equal(resp.StatusCode, http.StatusMethodNotAllowed)
+
 	a.NotError(o.close())
 	<-exit
 }
@@ -16,6 +16,7 @@ import (
 type Archives struct {
 	Title       string
 	Permalink   string
+	Path        string
 	Keywords    string
 	Description string
 	Archives    []*Archive
@@ -72,19 +73,22 @@ func buildArchives(conf *loader.Config, posts []*Post) (*Archives, error) {
 		return list[i].date.Before(list[j].date)
 	})
 
-	if conf.Archive.Keywords == "" {
-		conf.Archive.Keywords = conf.Keywords
+	keywords := conf.Archive.Keywords
+	if keywords == "" {
+		keywords = conf.Keywords
 	}
 
-	if conf.Archive.Description == "" {
-		conf.Archive.Description = conf.Description
+	description := conf.Archive.Description
+	if description == "" {
+		description = conf.Description
 	}
 
 	return &Archives{
 		Title:       conf.Archive.Title,
 		Permalink:   BuildURL(conf.URL, vars.ArchiveFilename),
-		Keywords:    conf.Archive.Keywords,
-		Description: conf.Archive.Description,
+		Path:        vars.ArchiveFilename,
+		Keywords:    keywords,
+		Description: description,
 		Archives:    list,
 	}, nil
 }
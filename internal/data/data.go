@@ -6,8 +6,10 @@
 package data
 
 import (
+	"context"
 	"io/fs"
 	"path"
+	"slices"
 	"time"
 
 	"github.com/caixw/blogit/v2/internal/loader"
@@ -29,78 +31,174 @@ type (
 		Highlights  []*Highlight
 		Menus       []*loader.Link
 
-		RSS     *RSS
-		Atom    *RSS
-		Sitemap *Sitemap
-		Robots  *Robots
-		Profile *Profile
+		RSS         *RSS
+		Atom        *RSS
+		Sitemap     *Sitemap
+		Robots      *Robots
+		Profile     *Profile
+		Minify      *loader.Minify      // 为空表示不需要压缩
+		Compress    *loader.Compress    // 为空表示不需要生成预压缩文件
+		Fingerprint *loader.Fingerprint // 为空表示不需要生成资源指纹
 
 		Uptime   time.Time
 		Created  time.Time
 		Modified time.Time
 		Builded  time.Time // 最后次编译时间
 
+		// 下一次有文章发布或是过期的时间
+		//
+		// 零值表示没有，预览模式下始终为零值。
+		Scheduled time.Time
+
+		// 所有文章的字数以及阅读时间的总和，不包含不公开的文章和独立页面。
+		WordCount   int
+		CharCount   int
+		ReadingTime int
+
 		Tags     *Tags
+		Series   []*Series
 		Posts    []*Post
+		Unlisted []*Post // 不公开的文章，仅生成文章页面。
+		Pages    []*Post // 独立页面
 		Indexes  []*Index
 		Archives *Archives
+
+		Redirects     []*Redirect       // 文章旧地址的跳转页面
+		RedirectRules *loader.Redirects // 为空表示不需要生成服务端的重定向规则
+
+		// 多语言站点中所有语言的数据
+		//
+		// 第一个元素为默认语言，即根对象本身，其它语言的数据仅包含了各自的文章、索引页、标签、存档和订阅，
+		// 所有语言的 Languages 都指向同一个列表。非多语言站点为空。
+		Languages []*Data
 	}
 )
 
+// Options 加载数据时的选项
+type Options struct {
+	// 是否为预览模式，在预览模式下会加载草稿以及未到发布时间和已经过期的文章。
+	Preview bool
+
+	// 如果不为空，则会替换配置文件中的 URL 字段。
+	BaseURL string
+
+	// 文章的解析缓存，可以为空。
+	Cache *loader.Cache
+
+	// 同时解析文章的最大数量，小于等于 1 表示按顺序解析。
+	Concurrency int
+
+	// 执行加载过程中的各个阶段
+	//
+	// name 为阶段的名称，即 Phase 开头的常量，f 为该阶段的实际执行函数，
+	// 可用于统计各个阶段的耗时。为空表示直接执行 f。
+	Phase func(name string, f func() error) error
+}
+
+// 加载过程中的各个阶段
+const (
+	PhaseConfig  = "load.config"  // 加载 conf.yaml
+	PhaseTags    = "load.tags"    // 加载 tags.yaml 和 series.yaml
+	PhasePosts   = "load.posts"   // 加载文章以及独立页面
+	PhaseTheme   = "load.theme"   // 加载主题
+	PhaseProcess = "load.process" // 对加载的数据进行二次加工
+)
+
 // Load 加载并处理数据
 //
-// preview 表示是否为预览模式，在预览模式下会加载草稿同；
-// 如果 baseURL 不为空，则会替换配置文件中的 URL 字段。
-func Load(fs fs.FS, preview bool, baseURL string) (*Data, error) {
-	conf, err := loader.LoadConfig(fs, vars.ConfYAML)
+// ctx 可用于取消加载过程；
+// o 可以为空，表示采用默认值。
+func Load(ctx context.Context, fs fs.FS, o *Options) (*Data, error) {
+	if o == nil {
+		o = &Options{}
+	}
+	phase := o.Phase
+	if phase == nil {
+		phase = func(_ string, f func() error) error { return f() }
+	}
+
+	var conf *loader.Config
+	err := phase(PhaseConfig, func() (err error) {
+		conf, err = loader.LoadConfig(fs, vars.ConfYAML)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if baseURL != "" {
-		conf.URL = baseURL
+	if o.BaseURL != "" {
+		conf.URL = o.BaseURL
+	}
+
+	var tags *loader.Tags
+	var series *loader.SeriesList
+	err = phase(PhaseTags, func() (err error) {
+		if tags, err = loader.LoadTags(fs, vars.TagsYAML); err != nil {
+			return err
+		}
+		series, err = loader.LoadSeries(fs, vars.SeriesYAML)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	tags, err := loader.LoadTags(fs, vars.TagsYAML)
+	var posts, pages []*loader.Post
+	var scheduled time.Time
+	err = phase(PhasePosts, func() (err error) {
+		if posts, err = loader.LoadPosts(ctx, fs, o.Preview, o.Cache, o.Concurrency); err != nil {
+			return err
+		}
+		if pages, err = loader.LoadPages(ctx, fs, o.Preview, o.Cache, o.Concurrency); err != nil {
+			return err
+		}
+
+		if !o.Preview {
+			now := time.Now()
+			var next time.Time
+			posts, scheduled = loader.Schedule(posts, now)
+			if pages, next = loader.Schedule(pages, now); !next.IsZero() && (scheduled.IsZero() || next.Before(scheduled)) {
+				scheduled = next
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	posts, err := loader.LoadPosts(fs, preview)
+	var theme *loader.Theme
+	err = phase(PhaseTheme, func() (err error) {
+		theme, err = loader.LoadTheme(fs, conf.Theme)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	theme, err := loader.LoadTheme(fs, conf.Theme)
+	var d *Data
+	err = phase(PhaseProcess, func() (err error) {
+		d, err = build(conf, tags, series, posts, pages, theme)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return build(conf, tags, posts, theme)
+	d.Scheduled = scheduled
+	return d, nil
 }
 
-func build(conf *loader.Config, tags *loader.Tags, posts []*loader.Post, theme *loader.Theme) (*Data, error) {
+func build(conf *loader.Config, tags *loader.Tags, series *loader.SeriesList, posts, pages []*loader.Post, theme *loader.Theme) (*Data, error) {
 	var suffix string
 	if conf.TitleSeparator != "" {
 		suffix = conf.TitleSeparator + conf.Title
 	}
 
-	ps, err := buildPosts(conf, theme, posts)
-	if err != nil {
-		return nil, err
-	}
-
-	archives, err := buildArchives(conf, ps)
-	if err != nil {
-		return nil, err
-	}
-
-	ts, err := buildTags(conf, tags, ps)
+	pgs, err := buildPages(conf, theme, pages)
 	if err != nil {
 		return nil, err
 	}
 
-	created, modified := getPostDate(ps)
-
 	data := &Data{
 		URL:         conf.URL,
 		Title:       conf.Title,
@@ -112,28 +210,45 @@ func build(conf *loader.Config, tags *loader.Tags, posts []*loader.Post, theme *
 		License:     conf.License,
 		Theme:       newTheme(theme),
 		Highlights:  newHighlights(conf, theme),
-		Menus:       conf.Menus,
+		Menus:       buildMenus(conf, pgs),
 
-		Uptime:   conf.Uptime,
-		Builded:  time.Now(),
-		Created:  created,
-		Modified: modified,
+		Uptime:  conf.Uptime,
+		Builded: time.Now(),
 
-		Tags:     ts,
-		Posts:    ps,
-		Indexes:  buildIndexes(conf, ps),
-		Archives: archives,
+		Pages:       pgs,
+		Minify:      conf.Minify,
+		Compress:    conf.Compress,
+		Fingerprint: conf.Fingerprint,
 	}
 
-	// 获得一份按时间排序的列表，诸如 rss 等不应该受自定义排序的影响，始终以时间作为排序。
-	sorted := sortPostsByCreated(ps)
+	groups := groupPosts(conf, posts)
+	if err := data.buildLanguage(conf, conf, tags, theme, groups[conf.Language]); err != nil {
+		return nil, err
+	}
+	if err := buildLanguages(conf, tags, theme, data, groups); err != nil {
+		return nil, err
+	}
 
-	if conf.RSS != nil {
-		data.RSS = newRSS(conf, conf.RSS, vars.RssXML, theme.RSS, sorted)
+	// 系列、跳转和译文不区分语言
+	all := make([]*Post, 0, len(posts))
+	hidden := make([]*Post, 0, 10)
+	for _, d := range data.AllLanguages() {
+		all = append(all, d.Posts...)
+		hidden = append(hidden, d.Unlisted...)
 	}
-	if conf.Atom != nil {
-		data.Atom = newRSS(conf, conf.Atom, vars.AtomXML, theme.Atom, sorted)
+
+	if data.Series, err = buildSeries(conf, series, all); err != nil {
+		return nil, err
+	}
+	data.Redirects = buildRedirects(conf, all, hidden, pgs)
+	if err := buildTranslations(all, hidden, pgs); err != nil {
+		return nil, err
+	}
+
+	if err := checkPaths(data); err != nil {
+		return nil, err
 	}
+
 	if conf.Sitemap != nil {
 		data.Sitemap = newSitemap(conf, theme)
 	}
@@ -141,12 +256,73 @@ func build(conf *loader.Config, tags *loader.Tags, posts []*loader.Post, theme *
 		data.Robots = newRobots(conf, data.Sitemap)
 	}
 	if conf.Profile != nil {
-		data.Profile = newProfile(conf, sorted)
+		data.Profile = newProfile(conf, sortPostsByCreated(data.Posts))
+	}
+	if conf.Redirects != nil && len(data.Redirects) > 0 {
+		data.RedirectRules = conf.Redirects
 	}
 
 	return data, nil
 }
 
+// 生成当前语言的文章及其索引页、标签、存档和订阅等内容
+//
+// posts 为属于该语言的文章，包含了不公开的文章；
+// 文章的地址与语言无关，始终以 root 为基准生成，其它内容则以 conf 为基准。
+func (d *Data) buildLanguage(root, conf *loader.Config, tags *loader.Tags, theme *loader.Theme, posts []*loader.Post) error {
+	listed := make([]*loader.Post, 0, len(posts))
+	unlisted := make([]*loader.Post, 0, 5)
+	for _, p := range posts {
+		if p.State == loader.StateUnlisted {
+			unlisted = append(unlisted, p)
+		} else {
+			listed = append(listed, p)
+		}
+	}
+
+	ps, err := buildPosts(root, theme, listed)
+	if err != nil {
+		return err
+	}
+
+	ups, err := buildPages(root, theme, unlisted)
+	if err != nil {
+		return err
+	}
+
+	archives, err := buildArchives(conf, ps)
+	if err != nil {
+		return err
+	}
+
+	ts, err := buildTags(conf, tags, ps, ups)
+	if err != nil {
+		return err
+	}
+
+	buildRelated(conf, ps, append(slices.Clip(ps), ups...))
+
+	d.Posts = ps
+	d.Unlisted = ups
+	d.Tags = ts
+	d.Archives = archives
+	d.Indexes = buildIndexes(conf, ps)
+	d.Created, d.Modified = getPostDate(ps)
+	d.WordCount, d.CharCount, d.ReadingTime = sumStats(ps)
+
+	// 获得一份按时间排序的列表，诸如 rss 等不应该受自定义排序的影响，始终以时间作为排序。
+	sorted := sortPostsByCreated(ps)
+
+	if conf.RSS != nil {
+		d.RSS = newRSS(conf, conf.RSS, vars.RssXML, theme.RSS, sorted)
+	}
+	if conf.Atom != nil {
+		d.Atom = newRSS(conf, conf.Atom, vars.AtomXML, theme.Atom, sorted)
+	}
+
+	return nil
+}
+
 // BuildURL 将 p 添加到 baseURL 形成一条完整的 URL
 func BuildURL(baseURL string, p ...string) string {
 	if baseURL == "" || baseURL[len(baseURL)-1] != '/' {
@@ -5,7 +5,9 @@
 package data
 
 import (
+	"context"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/issue9/assert/v4"
@@ -16,7 +18,7 @@ import (
 func TestLoad(t *testing.T) {
 	a := assert.New(t, false)
 
-	data, err := Load(testdata.Source, false, "")
+	data, err := Load(context.Background(), testdata.Source, nil)
 	a.NotError(err).NotNil(data)
 
 	a.Equal(data.Icon.Type, "image/png").Equal(data.Icon.Sizes, "256x256")
@@ -30,12 +32,87 @@ func TestLoad(t *testing.T) {
 	a.Equal(2, len(data.Indexes)) // 3 篇文章，每页 2 篇，可分为 2 个索引页
 	a.Equal(data.URL, "https://example.com")
 
-	a.True(data.Builded.After(time.Time{}))
-
-	data, err = Load(testdata.Source, true, "https://example.com/v2")
+	// 独立页面
+	a.Equal(1, len(data.Pages))
+	about := data.Pages[0]
+	a.Equal(about.Path, "about.html").
+		Equal(about.Permalink, "https://example.com/about.html").
+		Equal(about.Template, "page").
+		Contains(about.JSONLD, `"@type":"WebPage"`).
+		Nil(about.Prev).Nil(about.Next).Empty(about.Tags)
+	for _, tag := range data.Tags.Tags {
+		a.NotContains(tag.Posts, about)
+	}
+	for _, archive := range data.Archives.Archives {
+		a.NotContains(archive.Posts, about)
+	}
+	a.NotContains(data.RSS.Posts, about).
+		NotContains(data.Indexes[0].Posts, about)
+	a.Equal(data.Menus[3].URL, about.Permalink).Equal(data.Menus[3].Text, "关于")
+
+	// 字数统计
+	words, chars, minutes := 0, 0, 0
+	for _, p := range data.Posts {
+		a.True(p.WordCount > 0).True(p.CharCount >= p.WordCount).True(p.ReadingTime > 0)
+		words += p.WordCount
+		chars += p.CharCount
+		minutes += p.ReadingTime
+	}
+	a.Equal(data.WordCount, words).Equal(data.CharCount, chars).Equal(data.ReadingTime, minutes)
+	for _, tag := range data.Tags.Tags {
+		a.True(tag.WordCount > 0).True(tag.WordCount <= data.WordCount)
+	}
+
+	// 系列
+	a.Length(data.Series, 1)
+	series := data.Series[0]
+	a.Equal(series.Slug, "example").Length(series.Posts, 2).
+		Equal(series.Posts[0].Slug, "posts/2020/12/p3"). // 指定了 seriesOrder
+		Equal(series.Posts[1].Slug, "posts/p1").
+		Equal(series.Posts[1].SeriesPrev, series.Posts[0])
+
+	a.True(data.Builded.After(time.Time{})).Zero(data.Scheduled)
+
+	data, err = Load(context.Background(), testdata.Source, &Options{Preview: true, BaseURL: "https://example.com/v2", Concurrency: 4})
 	a.NotError(err).NotNil(data)
 	a.Equal(data.URL, "https://example.com/v2")
 	a.Equal(4, len(data.Posts))
+
+	// Phase
+	phases := make([]string, 0, 5)
+	o := &Options{Phase: func(name string, f func() error) error {
+		phases = append(phases, name)
+		return f()
+	}}
+	data, err = Load(context.Background(), testdata.Source, o)
+	a.NotError(err).NotNil(data).
+		Equal(phases, []string{PhaseConfig, PhaseTags, PhasePosts, PhaseTheme, PhaseProcess})
+}
+
+func TestLoad_unlisted(t *testing.T) {
+	a := assert.New(t, false)
+
+	fsys := testdata.MapFS(t)
+	fsys["posts/unlisted.md"] = &fstest.MapFile{Data: []byte("---\ntitle: unlisted\ncreated: 2021-01-01T00:00:00Z\nstate: unlisted\ntags: [api, git]\n---\n")}
+
+	data, err := Load(context.Background(), fsys, nil)
+	a.NotError(err).NotNil(data)
+	a.Equal(3, len(data.Posts)).Length(data.Unlisted, 1)
+
+	unlisted := data.Unlisted[0]
+	a.True(unlisted.Unlisted).
+		Equal(unlisted.Path, "posts/unlisted.html").
+		Nil(unlisted.Prev).Nil(unlisted.Next).
+		Length(unlisted.Tags, 1).Equal(unlisted.Tags[0].Slug, "api") // git 没有其它文章，不会生成标签页。
+	a.NotContains(unlisted.Tags[0].Posts, unlisted).
+		NotContains(data.RSS.Posts, unlisted).
+		NotContains(data.Atom.Posts, unlisted).
+		NotContains(data.Profile.Posts, unlisted).
+		NotContains(data.Indexes[0].Posts, unlisted)
+	for _, archive := range data.Archives.Archives {
+		a.NotContains(archive.Posts, unlisted)
+	}
+	a.Equal(data.Created, data.Posts[0].Created) // 不影响最后的创建时间
 }
 
 func TestBuildURL(t *testing.T) {
@@ -11,6 +11,12 @@ import (
 	"github.com/caixw/blogit/v2/internal/loader"
 )
 
+// JSON-LD 的类型
+const (
+	ldTypeBlogPosting = "BlogPosting" // 文章
+	ldTypeWebPage     = "WebPage"     // 独立页面
+)
+
 type ldBlogPosting struct {
 	ldCreativeWork
 }
@@ -26,6 +32,7 @@ type ldCreativeWork struct {
 	License  string      `json:"license,omitempty"`
 	Keywords string      `json:"keywords,omitempty"`
 	Language string      `json:"inLanguage,omitempty"`
+	URL      string      `json:"url,omitempty"`
 }
 
 type ldPerson struct {
@@ -35,17 +42,18 @@ type ldPerson struct {
 	URL   string `json:"url,omitempty"`
 }
 
-func newLDBlogPosting(p *loader.Post) *ldBlogPosting {
+func newLDBlogPosting(p *loader.Post, typ, url string) *ldBlogPosting {
 	blog := &ldBlogPosting{
 		ldCreativeWork: ldCreativeWork{
 			Context:  "https://schema.org/",
-			Type:     "BlogPosting",
+			Type:     typ,
 			Headline: p.Title,
 			Created:  p.Created,
 			Modified: p.Modified,
 			License:  p.License.URL,
 			Keywords: p.Keywords,
 			Language: p.Language,
+			URL:      url,
 		},
 	}
 
@@ -61,8 +69,8 @@ func newLDBlogPosting(p *loader.Post) *ldBlogPosting {
 	return blog
 }
 
-func buildPostLD(p *loader.Post) (string, error) {
-	data, err := json.Marshal(newLDBlogPosting(p))
+func buildPostLD(p *loader.Post, typ, url string) (string, error) {
+	data, err := json.Marshal(newLDBlogPosting(p, typ, url))
 	if err != nil {
 		return "", err
 	}
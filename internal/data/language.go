@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	"path"
+	"slices"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// AllLanguages 返回所有语言的数据
+//
+// 非多语言站点仅返回 d 本身。
+func (d *Data) AllLanguages() []*Data {
+	if len(d.Languages) == 0 {
+		return []*Data{d}
+	}
+	return d.Languages
+}
+
+// 按语言对文章进行分组
+//
+// 语言不在 conf.Languages 中的文章，都归为默认语言。
+func groupPosts(conf *loader.Config, posts []*loader.Post) map[string][]*loader.Post {
+	groups := make(map[string][]*loader.Post, len(conf.Languages)+1)
+	for _, p := range posts {
+		lang := conf.Language
+		if slices.ContainsFunc(conf.Languages, func(l *loader.Language) bool { return l.ID == p.Language }) {
+			lang = p.Language
+		}
+		groups[lang] = append(groups[lang], p)
+	}
+	return groups
+}
+
+// 生成除默认语言之外的其它语言的数据
+//
+// 其它语言与默认语言共享 d 中的主题、菜单以及独立页面等内容，
+// 但有各自的文章、索引页、标签、存档和订阅，除文章之外的页面都位于该语言的目录之下。
+func buildLanguages(conf *loader.Config, tags *loader.Tags, theme *loader.Theme, d *Data, groups map[string][]*loader.Post) error {
+	if len(conf.Languages) == 0 {
+		return nil
+	}
+
+	langs := make([]*Data, 0, len(conf.Languages)+1)
+	langs = append(langs, d)
+	for _, l := range conf.Languages {
+		c := *conf
+		c.URL = BuildURL(conf.URL, l.Path) + "/" // 指向目录下的 index.html
+		c.Language = l.ID
+		if l.Title != "" {
+			c.Title = l.Title
+		}
+		if l.Subtitle != "" {
+			c.Subtitle = l.Subtitle
+		}
+		if l.Keywords != "" {
+			c.Keywords = l.Keywords
+		}
+		if l.Description != "" {
+			c.Description = l.Description
+		}
+
+		var suffix string
+		if c.TitleSeparator != "" {
+			suffix = c.TitleSeparator + c.Title
+		}
+
+		ld := &Data{
+			URL:         c.URL,
+			Title:       c.Title,
+			Subtitle:    c.Subtitle,
+			TitleSuffix: suffix,
+			Icon:        d.Icon,
+			Language:    l.ID,
+			Author:      d.Author,
+			License:     d.License,
+			Theme:       d.Theme,
+			Highlights:  d.Highlights,
+			Menus:       d.Menus,
+			Uptime:      d.Uptime,
+			Builded:     d.Builded,
+		}
+		if err := ld.buildLanguage(conf, &c, tags, theme, groups[l.ID]); err != nil {
+			return err
+		}
+		ld.movePaths(conf, theme, l.Path)
+
+		langs = append(langs, ld)
+	}
+
+	for _, l := range langs {
+		l.Languages = langs
+	}
+
+	return nil
+}
+
+// 将索引页、标签、存档和订阅等文件移至 dir 目录之下
+//
+// 这些内容的链接在生成时已经包含了 dir，仅需要修改文件路径，
+// 但订阅中引用的 XSL 文件位于主题中，需要以 conf.URL 为基准重新生成。
+func (d *Data) movePaths(conf *loader.Config, theme *loader.Theme, dir string) {
+	for _, index := range d.Indexes {
+		index.Path = path.Join(dir, index.Path)
+	}
+
+	d.Tags.Path = path.Join(dir, d.Tags.Path)
+	for _, t := range d.Tags.Tags {
+		t.Path = path.Join(dir, t.Path)
+	}
+
+	if d.Archives != nil {
+		d.Archives.Path = path.Join(dir, d.Archives.Path)
+	}
+
+	if d.RSS != nil {
+		d.RSS.Path = path.Join(dir, d.RSS.Path)
+		if theme.RSS != "" {
+			d.RSS.XSLPermalink = buildThemeURL(conf.URL, conf.Theme, theme.RSS)
+		}
+	}
+	if d.Atom != nil {
+		d.Atom.Path = path.Join(dir, d.Atom.Path)
+		if theme.Atom != "" {
+			d.Atom.XSLPermalink = buildThemeURL(conf.URL, conf.Theme, theme.Atom)
+		}
+	}
+}
+
+// 关联各篇文章的译文
+//
+// translationKey 相同的文章互为译文，但同一语言只能有一篇。
+// 不公开的文章可以将公开的文章作为译文，反之则不行。
+func buildTranslations(posts ...[]*Post) error {
+	keys := make(map[string][]*Post, 10)
+	for _, ps := range posts {
+		for _, p := range ps {
+			if p.translationKey == "" {
+				continue
+			}
+
+			for _, t := range keys[p.translationKey] {
+				if t.Language == p.Language {
+					return &loader.FieldError{File: p.Slug + vars.MarkdownExt, Field: "translationKey", Message: loader.DupValue, Value: p.translationKey}
+				}
+			}
+			keys[p.translationKey] = append(keys[p.translationKey], p)
+		}
+	}
+
+	for _, ps := range keys {
+		for _, p := range ps {
+			for _, t := range ps {
+				// 不公开的文章不能出现在公开文章的译文中，否则会通过 hreflang 和 sitemap 暴露其地址。
+				if t != p && (p.Unlisted || !t.Unlisted) {
+					p.Translations = append(p.Translations, t)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/testdata"
+)
+
+func TestBuildTranslations(t *testing.T) {
+	a := assert.New(t, false)
+
+	zh := &Post{Slug: "posts/zh", Language: "cmn-Hans", translationKey: "hello"}
+	en := &Post{Slug: "posts/en", Language: "en", translationKey: "hello"}
+	ja := &Post{Slug: "posts/ja", Language: "ja", translationKey: "hello"}
+	other := &Post{Slug: "posts/other", Language: "en"}
+	a.NotError(buildTranslations([]*Post{zh, en, other}, []*Post{ja}))
+	a.Equal(zh.Translations, []*Post{en, ja}).
+		Equal(en.Translations, []*Post{zh, ja}).
+		Equal(ja.Translations, []*Post{zh, en}).
+		Empty(other.Translations)
+
+	// 不公开的文章
+	zh = &Post{Slug: "posts/zh", Language: "cmn-Hans", translationKey: "hidden"}
+	hidden := &Post{Slug: "posts/hidden", Language: "en", translationKey: "hidden", Unlisted: true}
+	a.NotError(buildTranslations([]*Post{zh}, []*Post{hidden}))
+	a.Empty(zh.Translations).
+		Equal(hidden.Translations, []*Post{zh})
+
+	// 同一语言有多篇
+	dup := &Post{Slug: "posts/dup", Language: "en", translationKey: "hello"}
+	err := buildTranslations([]*Post{zh, en, dup})
+	a.Error(err)
+	ferr, ok := err.(*loader.FieldError)
+	a.True(ok).Equal(ferr.Field, "translationKey").Equal(ferr.File, "posts/dup.md")
+}
+
+func TestLoad_languages(t *testing.T) {
+	a := assert.New(t, false)
+
+	fsys := testdata.MapFS(t)
+	fsys["conf.yaml"].Data = append(fsys["conf.yaml"].Data, []byte("\nlanguages:\n  - id: en\n    title: English\n  - id: ja\n")...)
+	fsys["posts/zh.md"] = &fstest.MapFile{Data: []byte("---\ntitle: zh\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\ntranslationKey: hello\n---\n")}
+	fsys["posts/en.md"] = &fstest.MapFile{Data: []byte("---\ntitle: en\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\nlanguage: en\ntranslationKey: hello\n---\n")}
+
+	data, err := Load(context.Background(), fsys, nil)
+	a.NotError(err).NotNil(data).
+		Length(data.Languages, 3).
+		Equal(data.Languages[0], data)
+
+	en := data.Languages[1]
+	a.Equal(en.Language, "en").
+		Equal(en.Title, "English").
+		Equal(en.URL, "https://example.com/en/").
+		Equal(en.Languages, data.Languages).
+		Length(en.Posts, 1).
+		Equal(en.Posts[0].Title, "en").
+		Equal(en.Tags.Path, "en/tags.html").
+		Equal(en.Archives.Path, "en/archive.html").
+		Equal(en.Indexes[0].Path, "en/index.html")
+	a.Length(en.Tags.Tags, 1).
+		Equal(en.Tags.Tags[0].Slug, "api").
+		Equal(en.Tags.Tags[0].Path, "en/tags/api.html")
+
+	// 英文的文章不会出现在默认语言中
+	for _, p := range data.Posts {
+		a.NotEqual(p.Language, "en")
+		if p.Title == "zh" {
+			a.Length(p.Translations, 1).Equal(p.Translations[0], en.Posts[0])
+		}
+	}
+
+	// 没有文章的语言
+	ja := data.Languages[2]
+	a.Equal(ja.Language, "ja").
+		Empty(ja.Posts).
+		Equal(ja.Title, data.Title)
+
+	// 同一语言的译文重复
+	fsys["posts/en2.md"] = &fstest.MapFile{Data: []byte("---\ntitle: en2\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\nlanguage: en\ntranslationKey: hello\n---\n")}
+	data, err = Load(context.Background(), fsys, nil)
+	a.Error(err).Nil(data)
+}
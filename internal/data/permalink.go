@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	"strings"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func permalinks(conf *loader.Config) *loader.Permalinks {
+	if conf.Permalinks == nil {
+		return &loader.Permalinks{}
+	}
+	return conf.Permalinks
+}
+
+// 根据地址格式 pattern 生成文件路径以及对应的链接
+//
+// r 用于替换 pattern 中的占位符；pattern 为空时，直接采用 def 作为文件路径。
+func buildPermalink(baseURL, pattern string, r *strings.Replacer, def string) (p, permalink string) {
+	if pattern == "" {
+		return def, BuildURL(baseURL, def)
+	}
+
+	p = r.Replace(pattern)
+	if strings.HasSuffix(p, "/") { // 目录形式的地址
+		return p + vars.IndexFilename, BuildURL(baseURL, p) + "/"
+	}
+	return p, BuildURL(baseURL, p)
+}
+
+// 检测生成的页面之间是否存在相同的路径
+//
+// 采用自定义的地址格式时，不同类型的页面之间可能会产生冲突，文章的旧地址也不能与其它页面相同。
+func checkPaths(d *Data) error {
+	paths := make(map[string]struct{}, len(d.Posts)+len(d.Unlisted)+len(d.Pages)+len(d.Tags.Tags)+len(d.Series)+len(d.Indexes)+5)
+	check := func(p string) *loader.FieldError {
+		if _, found := paths[p]; found {
+			return &loader.FieldError{File: vars.ConfYAML, Field: "permalinks", Message: loader.DupValue, Value: p}
+		}
+		paths[p] = struct{}{}
+		return nil
+	}
+
+	for _, l := range d.AllLanguages() {
+		if err := check(l.Tags.Path); err != nil {
+			return err
+		}
+		if l.Archives != nil {
+			if err := check(l.Archives.Path); err != nil {
+				return err
+			}
+		}
+
+		for _, index := range l.Indexes {
+			if err := check(index.Path); err != nil {
+				return err
+			}
+		}
+		for _, posts := range [][]*Post{l.Posts, l.Unlisted} {
+			for _, p := range posts {
+				if err := check(p.Path); err != nil {
+					return err
+				}
+			}
+		}
+		for _, t := range l.Tags.Tags {
+			if err := check(t.Path); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, p := range d.Pages {
+		if err := check(p.Path); err != nil {
+			return err
+		}
+	}
+	for _, s := range d.Series {
+		if err := check(s.Path); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range d.Redirects {
+		if err := check(r.Path); err != nil {
+			err.File = r.Post.Slug + vars.MarkdownExt
+			err.Field = "aliases"
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+)
+
+func TestBuildPermalink(t *testing.T) {
+	a := assert.New(t, false)
+	r := strings.NewReplacer(":slug", "p1")
+
+	p, permalink := buildPermalink("https://example.com", "", r, "posts/p1.html")
+	a.Equal(p, "posts/p1.html").Equal(permalink, "https://example.com/posts/p1.html")
+
+	p, permalink = buildPermalink("https://example.com", "post/:slug/", r, "posts/p1.html")
+	a.Equal(p, "post/p1/index.html").Equal(permalink, "https://example.com/post/p1/")
+
+	p, permalink = buildPermalink("https://example.com/", "post/:slug.html", r, "posts/p1.html")
+	a.Equal(p, "post/p1.html").Equal(permalink, "https://example.com/post/p1.html")
+}
+
+func TestLoad_permalinks(t *testing.T) {
+	a := assert.New(t, false)
+	fsys := testdata.MapFS(t)
+	fsys["conf.yaml"].Data = append(fsys["conf.yaml"].Data, []byte(`
+permalinks:
+  post: /:year/:month/:slug/
+  page: /:slug/
+  tag: /tag/:slug/
+  series: /series/:slug.html
+  index: /page/:page/
+`)...)
+
+	data, err := Load(context.Background(), fsys, nil)
+	a.NotError(err).NotNil(data)
+
+	for _, p := range data.Posts {
+		if p.Slug == "posts/p1" {
+			a.Equal(p.Path, "2020/01/p1/index.html").
+				Equal(p.Permalink, "https://example.com/2020/01/p1/")
+		}
+	}
+	a.Equal(data.Pages[0].Path, "about/index.html").
+		Equal(data.Pages[0].Permalink, "https://example.com/about/")
+	a.Equal(data.Tags.Tags[0].Path, "tag/"+data.Tags.Tags[0].Slug+"/index.html")
+	a.Equal(data.Series[0].Path, "series/example.html")
+	a.Equal(data.Indexes[0].Path, "index.html").
+		Equal(data.Indexes[1].Path, "page/2/index.html").
+		Equal(data.Indexes[1].Permalink, "https://example.com/page/2/")
+	a.Equal(data.RSS.Posts[0].Permalink, data.Posts[0].Permalink)
+
+	// 正文中的相对地址，在页面移动之后需要转换。
+	for _, p := range data.Posts {
+		if p.Slug == "posts/2020/p2" {
+			a.Contains(p.Content, `src="https://example.com/posts/2020/img.svg"`).
+				Contains(p.JSONLD, `"url":"https://example.com/2020/01/p2/"`)
+		}
+	}
+
+	// 地址冲突
+	fsys = testdata.MapFS(t)
+	fsys["conf.yaml"].Data = append(fsys["conf.yaml"].Data, []byte(`
+permalinks:
+  page: /tags/:slug.html
+  tag: /tags/:slug.html
+`)...)
+	fsys["pages/api.md"] = &fstest.MapFile{Data: []byte("---\ntitle: api\n---\n")}
+	data, err = Load(context.Background(), fsys, nil)
+	a.Error(err).Nil(data)
+}
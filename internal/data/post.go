@@ -6,8 +6,13 @@ package data
 
 import (
 	"fmt"
+	"html"
 	"math"
+	neturl "net/url"
+	gopath "path"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,26 +38,57 @@ type Index struct {
 
 // Post 文章详情
 type Post struct {
-	Permalink string
-	Slug      string
-	Path      string
-	Title     string
-	Created   time.Time
-	Modified  time.Time
-	Tags      []*Tag
-	tags      []string
-	Language  string
-	Authors   []*loader.Author
-	License   *loader.Link
-	Keywords  string
-	Summary   string
-	Content   string
-	Image     string
-	Prev      *Post
-	Next      *Post
-	Template  string
-	JSONLD    string
-	TOC       []loader.Header
+	Permalink   string
+	Slug        string
+	Path        string
+	Title       string
+	Created     time.Time
+	Modified    time.Time
+	Tags        []*Tag
+	tags        []string
+	Language    string
+	Authors     []*loader.Author
+	License     *loader.Link
+	Keywords    string
+	Summary     string // 摘要，未指定时会从正文中提取。
+	SummaryText string // 摘要的纯文本形式
+	Content     string
+	Image       string
+	Prev        *Post
+	Next        *Post
+	Template    string
+	JSONLD      string
+	TOC         []loader.Header
+	Unlisted    bool // 不公开的文章，页面不应该被搜索引擎收录。
+
+	// 字数统计，均不包含代码块。
+	//
+	// WordCount 中日文按字符计算，其它语言按单词计算；
+	// CharCount 为除空白之外的字符数量；ReadingTime 为阅读所需的分钟数。
+	WordCount   int
+	CharCount   int
+	ReadingTime int
+
+	// 相关的文章，按相似度从高到低排列，仅在 conf.yaml 中指定了 related 时才有值。
+	Related []*Post
+
+	// 其它语言的译文，不包含当前文章。
+	Translations   []*Post
+	translationKey string
+
+	// 所属的系列，以及在系列中的位置和前后文章，不属于任何系列时均为空值。
+	//
+	// SeriesIndex 从 1 开始，与 Prev 和 Next 不同，SeriesPrev 和 SeriesNext 仅在同一系列中查找。
+	Series      *Series
+	SeriesIndex int
+	SeriesPrev  *Post
+	SeriesNext  *Post
+	series      string
+	seriesOrder int
+
+	aliases []string
+	draft   bool
+	text    string // 正文的纯文本，用于计算相关文章。
 }
 
 func buildPosts(conf *loader.Config, theme *loader.Theme, posts []*loader.Post) ([]*Post, error) {
@@ -72,10 +108,45 @@ func buildPosts(conf *loader.Config, theme *loader.Theme, posts []*loader.Post)
 	return ps, nil
 }
 
+// 生成独立页面
+//
+// 独立页面与文章采用相同的数据结构，但不会与标签、索引、订阅和存档产生关联，也没有上一篇和下一篇。
+// 不公开的文章也采用此方式生成。
+func buildPages(conf *loader.Config, theme *loader.Theme, pages []*loader.Post) ([]*Post, error) {
+	ps := make([]*Post, 0, len(pages))
+	for _, p := range pages {
+		page, err := buildPost(conf, theme, p)
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, page)
+	}
+	return ps, nil
+}
+
+// 根据 conf.yaml 中的菜单生成实际的菜单
+//
+// 地址为独立页面 slug 的菜单项，会被替换为该页面的地址。
+func buildMenus(conf *loader.Config, pages []*Post) []*loader.Link {
+	if len(pages) == 0 {
+		return conf.Menus
+	}
+
+	menus := make([]*loader.Link, 0, len(conf.Menus))
+	for _, m := range conf.Menus {
+		if p, found := sliceutil.At(pages, func(p *Post, _ int) bool { return p.Slug == m.URL }); found {
+			m = &loader.Link{URL: p.Permalink, Text: m.Text}
+		}
+		menus = append(menus, m)
+	}
+	return menus
+}
+
 func buildIndexes(conf *loader.Config, posts []*Post) []*Index {
 	size := int(math.Ceil(float64(len(posts)) / float64(conf.Index.Size)))
 	indexes := make([]*Index, 0, size)
 	hasVerbs := strings.Contains(conf.Index.Title, "%d")
+	pattern := permalinks(conf).Index
 
 	for page := 0; page < size; page++ {
 		start := page * conf.Index.Size
@@ -100,8 +171,8 @@ func buildIndexes(conf *loader.Config, posts []*Post) []*Index {
 			index.Path = vars.IndexFilename
 			index.Permalink = conf.URL
 		} else {
-			index.Path = fmt.Sprintf(vars.IndexFilenameFormat, index.Index)
-			index.Permalink = BuildURL(conf.URL, index.Path)
+			r := strings.NewReplacer(loader.PermalinkPage, strconv.Itoa(index.Index))
+			index.Path, index.Permalink = buildPermalink(conf.URL, pattern, r, fmt.Sprintf(vars.IndexFilenameFormat, index.Index))
 		}
 
 		indexes = append(indexes, index)
@@ -149,37 +220,144 @@ func buildPost(conf *loader.Config, theme *loader.Theme, p *loader.Post) (*Post,
 		}
 	}
 
+	// 默认情况下，独立页面输出在根目录下，页面包则输出到其所在的目录。
+	perms := permalinks(conf)
+	path, ldType, pattern := p.Slug, ldTypeBlogPosting, perms.Post
+	r := strings.NewReplacer(
+		loader.PermalinkYear, p.Created.Format("2006"),
+		loader.PermalinkMonth, p.Created.Format("01"),
+		loader.PermalinkDay, p.Created.Format("02"),
+		loader.PermalinkSlug, gopath.Base(p.Slug),
+		loader.PermalinkPath, strings.TrimPrefix(p.Slug, vars.PostsDir+"/"),
+	)
+	if page, found := strings.CutPrefix(p.Slug, vars.PagesDir+"/"); found {
+		path, ldType, pattern = page, ldTypeWebPage, perms.Page
+		r = strings.NewReplacer(loader.PermalinkSlug, page)
+	}
+	if p.Bundle {
+		path += "/" + vars.IndexFilename
+	} else {
+		path += vars.Ext
+	}
+	path, permalink := buildPermalink(conf.URL, pattern, r, path)
+
+	// 未指定摘要时，优先采用 <!--more--> 之前的内容，其次才是截取正文的纯文本。
+	var text string
+	switch {
+	case p.Summary != "":
+		text = htmlToText(p.Summary)
+	case p.Excerpt != "":
+		p.Summary = p.Excerpt
+		text = htmlToText(p.Excerpt)
+	case conf.Excerpt > 0 && p.Text != "":
+		text = loader.Excerpt(p.Text, conf.Excerpt)
+		p.Summary = "<p>" + html.EscapeString(text) + "</p>"
+	}
+
+	// 摘要还会出现在订阅以及索引页等位置，需要以 markdown 文件所在的目录为基准转换成绝对地址；
+	// 正文仅在生成的 HTML 与 markdown 文件不在同一目录时才需要转换。
+	dir := p.Slug
+	if !p.Bundle {
+		dir = gopath.Dir(dir)
+	}
+	base, err := neturl.Parse(BuildURL(conf.URL, dir) + "/")
+	if err != nil {
+		return nil, err
+	}
+	p.Summary = rewriteRelativeURLs(p.Summary, base)
+	if gopath.Dir(path) != dir {
+		p.Content = rewriteRelativeURLs(p.Content, base)
+	}
+
 	// NOTE: p.JSONLD 用到以上的一些变量，比如 p.License 等，所以需要放在最后初始化。
 	if p.JSONLD == "" {
-		ld, err := buildPostLD(p)
+		ld, err := buildPostLD(p, ldType, permalink)
 		if err != nil {
 			return nil, err
 		}
 		p.JSONLD = ld
 	}
 
-	path := p.Slug + vars.Ext
 	return &Post{
-		Permalink: BuildURL(conf.URL, path),
-		Slug:      p.Slug,
-		Path:      path,
-		Title:     p.Title,
-		Created:   p.Created,
-		Modified:  p.Modified,
-		tags:      p.Tags,
-		Language:  p.Language,
-		Authors:   p.Authors,
-		License:   p.License,
-		Keywords:  p.Keywords,
-		Summary:   p.Summary,
-		Content:   p.Content,
-		Image:     p.Image,
-		Template:  p.Template,
-		JSONLD:    p.JSONLD,
-		TOC:       p.TOC,
+		Permalink:   permalink,
+		Slug:        p.Slug,
+		Path:        path,
+		Title:       p.Title,
+		Created:     p.Created,
+		Modified:    p.Modified,
+		tags:        p.Tags,
+		Language:    p.Language,
+		Authors:     p.Authors,
+		License:     p.License,
+		Keywords:    p.Keywords,
+		Summary:     p.Summary,
+		SummaryText: text,
+		WordCount:   p.Stats.CJK + p.Stats.Words,
+		CharCount:   p.Stats.Chars,
+		ReadingTime: readingTime(conf, p.Stats),
+		Content:     p.Content,
+		Image:       p.Image,
+		Template:    p.Template,
+		JSONLD:      p.JSONLD,
+		TOC:         p.TOC,
+		Unlisted:    p.State == loader.StateUnlisted,
+
+		series:      p.Series,
+		seriesOrder: p.SeriesOrder,
+
+		aliases: p.Aliases,
+		draft:   p.State == loader.StateDraft,
+		text:    p.Text,
+
+		translationKey: p.TranslationKey,
 	}, nil
 }
 
+var relativeURLExpr = regexp.MustCompile(`(\s(?:src|href)=")([^"]*)(")`)
+
+// 将 content 中 src 和 href 属性的相对地址转换为基于 base 的绝对地址
+func rewriteRelativeURLs(content string, base *neturl.URL) string {
+	if content == "" {
+		return content
+	}
+
+	return relativeURLExpr.ReplaceAllStringFunc(content, func(s string) string {
+		m := relativeURLExpr.FindStringSubmatch(s)
+		u, err := neturl.Parse(html.UnescapeString(m[2]))
+		if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+			return s
+		}
+		return m[1] + html.EscapeString(base.ResolveReference(u).String()) + m[3]
+	})
+}
+
+var htmlTagExpr = regexp.MustCompile(`<[^>]*>`)
+
+// 去掉 HTML 标签并合并空白字符
+func htmlToText(content string) string {
+	content = html.UnescapeString(htmlTagExpr.ReplaceAllString(content, " "))
+	return strings.Join(strings.Fields(content), " ")
+}
+
+// 计算阅读所需的分钟数，不足一分钟的按一分钟计算。
+func readingTime(conf *loader.Config, s loader.Stats) int {
+	r := conf.Reading
+	if r == nil {
+		r = &loader.Reading{CJK: loader.DefaultReadingCJK, Words: loader.DefaultReadingWords}
+	}
+	return int(math.Ceil(float64(s.CJK)/float64(r.CJK) + float64(s.Words)/float64(r.Words)))
+}
+
+// 统计 posts 的字数以及阅读时间的总和
+func sumStats(posts []*Post) (words, chars, minutes int) {
+	for _, p := range posts {
+		words += p.WordCount
+		chars += p.CharCount
+		minutes += p.ReadingTime
+	}
+	return
+}
+
 func postsPrevNext(posts []*Post) {
 	max := len(posts)
 	for i := 0; i < max; i++ {
@@ -5,6 +5,7 @@
 package data
 
 import (
+	"net/url"
 	"testing"
 	"time"
 
@@ -75,3 +76,97 @@ func TestSortPostsByCreated(t *testing.T) {
 		Equal(posts[2].Title, "3").
 		Equal(posts[3].Title, "4")
 }
+
+func TestRewriteRelativeURLs(t *testing.T) {
+	a := assert.New(t, false)
+	base, err := url.Parse("https://example.com/posts/2024/p1/")
+	a.NotError(err)
+
+	a.Equal(rewriteRelativeURLs("", base), "")
+	a.Equal(rewriteRelativeURLs(`<img src="img.png" /><a href="./sub/a.html?x=1&amp;y=2#h">a</a>`, base),
+		`<img src="https://example.com/posts/2024/p1/img.png" /><a href="https://example.com/posts/2024/p1/sub/a.html?x=1&amp;y=2#h">a</a>`)
+	a.Equal(rewriteRelativeURLs(`<img src="../img.png" />`, base), `<img src="https://example.com/posts/2024/img.png" />`)
+
+	// 不需要处理的链接
+	for _, s := range []string{
+		`<a href="#h">a</a>`,
+		`<a href="/posts/p1.html">a</a>`,
+		`<a href="https://example.com/posts/p1.html">a</a>`,
+		`<a href="//example.com/posts/p1.html">a</a>`,
+		`<a href="mailto:a@example.com">a</a>`,
+		`<img data-src="img.png" />`,
+	} {
+		a.Equal(rewriteRelativeURLs(s, base), s)
+	}
+}
+
+func TestBuildPost_bundle(t *testing.T) {
+	a := assert.New(t, false)
+	conf := &loader.Config{URL: "https://example.com", Author: &loader.Author{Name: "a"}, License: &loader.Link{URL: "https://example.com/license"}}
+	theme := &loader.Theme{Templates: []string{"post", "page"}}
+
+	p, err := buildPost(conf, theme, &loader.Post{
+		Slug:     "posts/2024/p1",
+		Bundle:   true,
+		Template: "post",
+		Summary:  `<img src="img.png" />`,
+		Content:  `<img src="img.png" />`,
+	})
+	a.NotError(err).
+		Equal(p.Path, "posts/2024/p1/index.html").
+		Equal(p.Permalink, "https://example.com/posts/2024/p1/index.html").
+		Equal(p.Summary, `<img src="https://example.com/posts/2024/p1/img.png" />`).
+		Equal(p.Content, `<img src="img.png" />`)
+
+	p, err = buildPost(conf, theme, &loader.Post{Slug: "posts/2024/p2", Template: "post", Summary: `<img src="img.png" />`})
+	a.NotError(err).
+		Equal(p.Path, "posts/2024/p2.html").
+		Equal(p.Summary, `<img src="https://example.com/posts/2024/img.png" />`)
+
+	p, err = buildPost(conf, theme, &loader.Post{Slug: "pages/about", Bundle: true, Template: "page"})
+	a.NotError(err).Equal(p.Path, "about/index.html")
+}
+
+func TestBuildPost_summary(t *testing.T) {
+	a := assert.New(t, false)
+	conf := &loader.Config{URL: "https://example.com", Author: &loader.Author{Name: "a"}, License: &loader.Link{URL: "https://example.com/license"}, Excerpt: 5}
+	theme := &loader.Theme{Templates: []string{"post"}}
+
+	p, err := buildPost(conf, theme, &loader.Post{Slug: "posts/p1", Template: "post", Summary: "<p>a &amp; b</p>", Text: "content"})
+	a.NotError(err).
+		Equal(p.Summary, "<p>a &amp; b</p>").
+		Equal(p.SummaryText, "a & b")
+
+	p, err = buildPost(conf, theme, &loader.Post{Slug: "posts/p1", Template: "post", Excerpt: `<p><img src="img.png" />more</p>`, Text: "content"})
+	a.NotError(err).
+		Equal(p.Summary, `<p><img src="https://example.com/posts/img.png" />more</p>`).
+		Equal(p.SummaryText, "more")
+
+	p, err = buildPost(conf, theme, &loader.Post{Slug: "posts/p1", Template: "post", Text: "<a> b 中文内容"})
+	a.NotError(err).
+		Equal(p.Summary, "<p>&lt;a&gt; b…</p>").
+		Equal(p.SummaryText, "<a> b…")
+
+	// Text 是已解码的纯文本，仅转义一次。
+	p, err = buildPost(conf, theme, &loader.Post{Slug: "posts/p1", Template: "post", Text: "a & b"})
+	a.NotError(err).
+		Equal(p.Summary, "<p>a &amp; b</p>").
+		Equal(p.SummaryText, "a & b")
+
+	conf.Excerpt = -1
+	p, err = buildPost(conf, theme, &loader.Post{Slug: "posts/p1", Template: "post", Text: "content"})
+	a.NotError(err).Empty(p.Summary).Empty(p.SummaryText)
+}
+
+func TestReadingTime(t *testing.T) {
+	a := assert.New(t, false)
+
+	conf := &loader.Config{}
+	a.Equal(readingTime(conf, loader.Stats{}), 0).
+		Equal(readingTime(conf, loader.Stats{CJK: 1}), 1).
+		Equal(readingTime(conf, loader.Stats{CJK: 600}), 2).
+		Equal(readingTime(conf, loader.Stats{CJK: 300, Words: 201}), 3)
+
+	conf.Reading = &loader.Reading{CJK: 100, Words: 100}
+	a.Equal(readingTime(conf, loader.Stats{CJK: 100, Words: 100}), 2)
+}
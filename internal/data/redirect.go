@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	gopath "path"
+	"strings"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// Redirect 从文章的旧地址跳转到新地址
+type Redirect struct {
+	Path      string // 跳转页面的文件路径
+	URL       string // 旧地址
+	Permalink string // 跳转的目标地址，即文章的地址。
+	Title     string // 文章的标题
+	Post      *Post
+}
+
+// 根据文章的 aliases 生成跳转列表
+func buildRedirects(conf *loader.Config, posts ...[]*Post) []*Redirect {
+	rs := make([]*Redirect, 0, 5)
+	for _, ps := range posts {
+		for _, p := range ps {
+			for _, alias := range p.aliases {
+				path, url := alias, BuildURL(conf.URL, alias)
+				if dir := strings.TrimSuffix(alias, "/"); dir != alias || gopath.Ext(alias) == "" {
+					path, url = gopath.Join(dir, vars.IndexFilename), BuildURL(conf.URL, dir)+"/"
+				}
+
+				rs = append(rs, &Redirect{
+					Path:      path,
+					URL:       url,
+					Permalink: p.Permalink,
+					Title:     p.Title,
+					Post:      p,
+				})
+			}
+		}
+	}
+	return rs
+}
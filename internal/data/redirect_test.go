@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/testdata"
+)
+
+func TestBuildRedirects(t *testing.T) {
+	a := assert.New(t, false)
+	conf := &loader.Config{URL: "https://example.com/blog"}
+
+	p1 := &Post{Title: "p1", Permalink: "https://example.com/blog/posts/p1.html", aliases: []string{"old/p1.html", "old/p1/"}}
+	p2 := &Post{Title: "p2", Permalink: "https://example.com/blog/posts/p2.html", aliases: []string{"2020/p2"}}
+	rs := buildRedirects(conf, []*Post{p1}, []*Post{p2})
+	a.Length(rs, 3)
+
+	a.Equal(rs[0].Path, "old/p1.html").
+		Equal(rs[0].URL, "https://example.com/blog/old/p1.html").
+		Equal(rs[0].Permalink, p1.Permalink).
+		Equal(rs[0].Post, p1)
+	a.Equal(rs[1].Path, "old/p1/index.html").
+		Equal(rs[1].URL, "https://example.com/blog/old/p1/")
+	a.Equal(rs[2].Path, "2020/p2/index.html").
+		Equal(rs[2].URL, "https://example.com/blog/2020/p2/").
+		Equal(rs[2].Title, "p2")
+}
+
+func TestLoad_redirects(t *testing.T) {
+	a := assert.New(t, false)
+
+	fsys := testdata.MapFS(t)
+	fsys["posts/alias.md"] = &fstest.MapFile{Data: []byte("---\ntitle: alias\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\naliases: [/old/alias.html]\n---\n")}
+	data, err := Load(context.Background(), fsys, nil)
+	a.NotError(err).NotNil(data).
+		Length(data.Redirects, 1).
+		Nil(data.RedirectRules)
+	a.Equal(data.Redirects[0].Path, "old/alias.html")
+
+	fsys["conf.yaml"].Data = append(fsys["conf.yaml"].Data, []byte("\nredirects:\n  netlify: true\n")...)
+	data, err = Load(context.Background(), fsys, nil)
+	a.NotError(err).NotNil(data).
+		NotNil(data.RedirectRules).
+		True(data.RedirectRules.Netlify)
+
+	// 与其它页面的地址冲突
+	fsys["posts/alias.md"].Data = []byte("---\ntitle: alias\ncreated: 2021-01-01T00:00:00Z\ntags: [api]\naliases: [/archive.html]\n---\n")
+	data, err = Load(context.Background(), fsys, nil)
+	a.Error(err).Nil(data)
+	ferr, ok := err.(*loader.FieldError)
+	a.True(ok).Equal(ferr.Field, "aliases").Equal(ferr.File, "posts/alias.md")
+}
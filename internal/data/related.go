@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+)
+
+// 为 posts 中的每一篇文章计算相关文章
+//
+// candidates 为可以作为相关文章的列表，其中的草稿会被忽略；
+// posts 可以包含 candidates 之外的文章，比如不公开的文章。
+func buildRelated(conf *loader.Config, candidates, posts []*Post) {
+	r := conf.Related
+	if r == nil {
+		return
+	}
+
+	cs := make([]*Post, 0, len(candidates))
+	for _, p := range candidates {
+		if !p.draft {
+			cs = append(cs, p)
+		}
+	}
+
+	var vectors map[*Post]map[string]float64
+	if r.Content > 0 {
+		vectors = tfidf(append(slices.Clip(cs), posts...))
+	}
+
+	type item struct {
+		post  *Post
+		score float64
+	}
+
+	for _, p := range posts {
+		items := make([]item, 0, len(cs))
+		for _, c := range cs {
+			if c == p {
+				continue
+			}
+
+			var score float64
+			if r.Tags > 0 {
+				score += r.Tags * jaccard(p.tags, c.tags)
+			}
+			if r.Content > 0 {
+				score += r.Content * cosine(vectors[p], vectors[c])
+			}
+			if score > 0 {
+				items = append(items, item{post: c, score: score})
+			}
+		}
+
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].score != items[j].score {
+				return items[i].score > items[j].score
+			}
+			return items[i].post.Created.After(items[j].post.Created)
+		})
+
+		if len(items) > r.Size {
+			items = items[:r.Size]
+		}
+		p.Related = make([]*Post, 0, len(items))
+		for _, i := range items {
+			p.Related = append(p.Related, i.post)
+		}
+	}
+}
+
+// 计算两个集合的 Jaccard 系数
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	set := make(map[string]struct{}, len(a))
+	for _, v := range a {
+		set[v] = struct{}{}
+	}
+
+	inter, union := 0, len(set)
+	seen := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		if _, found := seen[v]; found {
+			continue
+		}
+		seen[v] = struct{}{}
+
+		if _, found := set[v]; found {
+			inter++
+		} else {
+			union++
+		}
+	}
+
+	return float64(inter) / float64(union)
+}
+
+// 计算每篇文章正文的 TF-IDF 向量，返回的向量均已归一化。
+//
+// posts 中的同一篇文章只计算一次。
+func tfidf(posts []*Post) map[*Post]map[string]float64 {
+	tfs := make(map[*Post]map[string]float64, len(posts))
+	df := make(map[string]int, 1000)
+	for _, p := range posts {
+		if _, found := tfs[p]; found {
+			continue
+		}
+
+		terms := loader.Terms(p.text)
+		tf := make(map[string]float64, len(terms))
+		for _, t := range terms {
+			tf[t]++
+		}
+		for t := range tf {
+			df[t]++
+			tf[t] /= float64(len(terms))
+		}
+		tfs[p] = tf
+	}
+
+	n := float64(len(tfs))
+	for _, tf := range tfs {
+		var norm float64
+		for t, v := range tf {
+			v *= math.Log((1+n)/(1+float64(df[t]))) + 1
+			tf[t] = v
+			norm += v * v
+		}
+
+		norm = math.Sqrt(norm)
+		for t := range tf {
+			tf[t] /= norm
+		}
+	}
+
+	return tfs
+}
+
+// 计算两个已经归一化的向量的余弦相似度
+func cosine(a, b map[string]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	var sum float64
+	for t, v := range a {
+		sum += v * b[t]
+	}
+	return sum
+}
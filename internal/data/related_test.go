@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	"context"
+	"math"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/testdata"
+)
+
+func TestJaccard(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(jaccard(nil, []string{"a"}), 0.0).
+		Equal(jaccard([]string{"a", "b"}, []string{"a", "b"}), 1.0).
+		Equal(jaccard([]string{"a", "b"}, []string{"b", "c"}), 1.0/3).
+		Equal(jaccard([]string{"a"}, []string{"b"}), 0.0)
+}
+
+func TestTFIDF(t *testing.T) {
+	a := assert.New(t, false)
+
+	p1 := &Post{text: "中文分词 go"}
+	p2 := &Post{text: "中文分词 rust"}
+	p3 := &Post{text: "other content"}
+	vs := tfidf([]*Post{p1, p2, p3, p1})
+	a.Length(vs, 3)
+
+	a.True(math.Abs(cosine(vs[p1], vs[p1])-1) < 1e-9).
+		True(cosine(vs[p1], vs[p2]) > 0).
+		Equal(cosine(vs[p1], vs[p3]), 0.0)
+}
+
+func TestBuildRelated(t *testing.T) {
+	a := assert.New(t, false)
+	now := time.Now()
+
+	p1 := &Post{Slug: "p1", tags: []string{"a", "b"}, Created: now}
+	p2 := &Post{Slug: "p2", tags: []string{"a", "b"}, Created: now.Add(-time.Hour)}
+	p3 := &Post{Slug: "p3", tags: []string{"a", "c"}, Created: now.Add(-2 * time.Hour)}
+	p4 := &Post{Slug: "p4", tags: []string{"d"}, Created: now.Add(-3 * time.Hour)}
+	draft := &Post{Slug: "draft", tags: []string{"a", "b"}, Created: now, draft: true}
+	unlisted := &Post{Slug: "unlisted", tags: []string{"a", "b"}, Created: now}
+	ps := []*Post{p1, p2, p3, p4, draft}
+
+	// 未指定 related
+	buildRelated(&loader.Config{}, ps, append(ps, unlisted))
+	a.Nil(p1.Related)
+
+	conf := &loader.Config{Related: &loader.Related{Size: 2, Tags: 1}}
+	buildRelated(conf, ps, append(ps, unlisted))
+	a.Equal(p1.Related, []*Post{p2, p3}).
+		Equal(p2.Related, []*Post{p1, p3}).
+		Empty(p4.Related).
+		Equal(unlisted.Related, []*Post{p1, p2})
+
+	// 正文的相似度
+	p1.text = "中文分词"
+	p4.text = "中文分词"
+	conf = &loader.Config{Related: &loader.Related{Size: 2, Content: 1}}
+	buildRelated(conf, ps, ps)
+	a.Equal(p1.Related, []*Post{p4})
+}
+
+func TestLoad_related(t *testing.T) {
+	a := assert.New(t, false)
+
+	fsys := testdata.MapFS(t)
+	fsys["conf.yaml"].Data = append(fsys["conf.yaml"].Data, []byte("\nrelated:\n  size: 1\n  content: 0.5\n  tags: 1\n")...)
+	fsys["posts/unlisted.md"] = &fstest.MapFile{Data: []byte("---\ntitle: unlisted\ncreated: 2021-01-01T00:00:00Z\nstate: unlisted\ntags: [api, git]\n---\n")}
+
+	data, err := Load(context.Background(), fsys, nil)
+	a.NotError(err).NotNil(data)
+	for _, p := range data.Posts {
+		a.Length(p.Related, 1).
+			NotEqual(p.Related[0], p).
+			NotEqual(p.Related[0], data.Unlisted[0])
+	}
+	a.Length(data.Unlisted[0].Related, 1)
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/issue9/localeutil"
+	"github.com/issue9/sliceutil"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// Series 单个系列的内容
+type Series struct {
+	Permalink string
+	Slug      string
+	Path      string
+	Title     string
+	Keywords  string
+	Content   string  // 对该系列的详细描述
+	Posts     []*Post // 按系列中的顺序排列
+	Created   time.Time
+	Modified  time.Time
+}
+
+// 生成系列列表
+//
+// series 为空表示没有 series.yaml 文件，此时文章也不能指定系列。
+// 未关联任何文章的系列会被忽略。
+func buildSeries(conf *loader.Config, series *loader.SeriesList, ps []*Post) ([]*Series, error) {
+	var items []*loader.Series
+	if series != nil {
+		items = series.Series
+	}
+
+	ss := make([]*Series, 0, len(items))
+	for _, s := range items {
+		key := s.Keywords
+		if key == "" {
+			key = s.Slug
+			if s.Slug != s.Title {
+				key += "," + s.Title
+			}
+		}
+
+		p, permalink := buildPermalink(conf.URL, permalinks(conf).Series, strings.NewReplacer(loader.PermalinkSlug, s.Slug), path.Join(vars.SeriesDir, s.Slug+vars.Ext))
+		ss = append(ss, &Series{
+			Permalink: permalink,
+			Slug:      s.Slug,
+			Path:      p,
+			Title:     s.Title,
+			Keywords:  key,
+			Content:   s.Content,
+		})
+	}
+
+	for _, p := range ps {
+		if p.series == "" {
+			continue
+		}
+
+		s, found := sliceutil.At(ss, func(s *Series, _ int) bool { return s.Slug == p.series })
+		if !found {
+			return nil, &loader.FieldError{File: p.Slug, Message: localeutil.Phrase("not found"), Field: "series", Value: p.series}
+		}
+		s.Posts = append(s.Posts, p)
+		p.Series = s
+
+		if s.Created.Before(p.Created) {
+			s.Created = p.Created
+		}
+		if s.Modified.Before(p.Modified) {
+			s.Modified = p.Modified
+		}
+	}
+
+	ss = sliceutil.Delete(ss, func(s *Series, _ int) bool { return len(s.Posts) == 0 })
+	for _, s := range ss {
+		sortSeriesPosts(s.Posts)
+		seriesPrevNext(s.Posts)
+	}
+
+	return ss, nil
+}
+
+// 指定了顺序的排在前面，其余的按创建时间从早到晚排列。
+func sortSeriesPosts(posts []*Post) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		oi, oj := posts[i].seriesOrder, posts[j].seriesOrder
+		switch {
+		case oi != 0 && oj != 0 && oi != oj:
+			return oi < oj
+		case oi != 0 && oj == 0:
+			return true
+		case oi == 0 && oj != 0:
+			return false
+		default:
+			return posts[i].Created.Before(posts[j].Created)
+		}
+	})
+}
+
+func seriesPrevNext(posts []*Post) {
+	max := len(posts)
+	for i := 0; i < max; i++ {
+		post := posts[i]
+		post.SeriesIndex = i + 1
+		if i > 0 {
+			post.SeriesPrev = posts[i-1]
+		}
+		if i < max-1 {
+			post.SeriesNext = posts[i+1]
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/loader"
+)
+
+func TestBuildSeries(t *testing.T) {
+	a := assert.New(t, false)
+	conf := &loader.Config{URL: "https://example.com"}
+	now := time.Now()
+
+	p1 := &Post{Slug: "p1", Created: now, series: "s1"}
+	p2 := &Post{Slug: "p2", Created: now.Add(-time.Hour), series: "s1"}
+	p3 := &Post{Slug: "p3", Created: now.Add(time.Hour), series: "s1", seriesOrder: 1}
+	p4 := &Post{Slug: "p4"}
+	series := &loader.SeriesList{Series: []*loader.Series{
+		{Slug: "s1", Title: "S1", Content: "c1"},
+		{Slug: "s2", Title: "s2", Content: "c2"},
+	}}
+
+	ss, err := buildSeries(conf, series, []*Post{p1, p2, p3, p4})
+	a.NotError(err).Length(ss, 1) // s2 没有关联的文章
+	s1 := ss[0]
+	a.Equal(s1.Path, "series/s1.html").
+		Equal(s1.Permalink, "https://example.com/series/s1.html").
+		Equal(s1.Keywords, "s1,S1").
+		Equal(s1.Posts, []*Post{p3, p2, p1}).
+		Equal(s1.Created, p3.Created)
+
+	a.Equal(p3.Series, s1).Equal(p3.SeriesIndex, 1).Nil(p3.SeriesPrev).Equal(p3.SeriesNext, p2)
+	a.Equal(p2.SeriesIndex, 2).Equal(p2.SeriesPrev, p3).Equal(p2.SeriesNext, p1)
+	a.Equal(p1.SeriesIndex, 3).Equal(p1.SeriesPrev, p2).Nil(p1.SeriesNext)
+	a.Nil(p4.Series).Zero(p4.SeriesIndex)
+
+	// 不存在的系列
+	p5 := &Post{Slug: "p5", series: "not-exists"}
+	ss, err = buildSeries(conf, series, []*Post{p5})
+	a.Error(err).Nil(ss)
+
+	// 没有 series.yaml
+	ss, err = buildSeries(conf, nil, []*Post{p4})
+	a.NotError(err).Empty(ss)
+	ss, err = buildSeries(conf, nil, []*Post{p5})
+	a.Error(err).Nil(ss)
+}
@@ -21,6 +21,7 @@ import (
 type Tags struct {
 	Title       string
 	Permalink   string
+	Path        string
 	Keywords    string
 	Description string
 	Tags        []*Tag
@@ -39,21 +40,30 @@ type Tag struct {
 	Next      *Tag
 	Created   time.Time
 	Modified  time.Time
+
+	// 所有文章的字数以及阅读时间的总和
+	WordCount   int
+	CharCount   int
+	ReadingTime int
 }
 
-func buildTags(conf *loader.Config, tags *loader.Tags, ps []*Post) (*Tags, error) {
-	if tags.Keywords == "" {
-		tags.Keywords = conf.Keywords
+// unlisted 为不公开的文章，仅为其关联标签，不会出现在标签的文章列表中。
+func buildTags(conf *loader.Config, tags *loader.Tags, ps, unlisted []*Post) (*Tags, error) {
+	keywords := tags.Keywords
+	if keywords == "" {
+		keywords = conf.Keywords
 	}
-	if tags.Description == "" {
-		tags.Description = conf.Description
+	description := tags.Description
+	if description == "" {
+		description = conf.Description
 	}
 
 	ts := &Tags{
 		Title:       tags.Title,
 		Permalink:   BuildURL(conf.URL, vars.TagsFilename),
-		Keywords:    tags.Keywords,
-		Description: tags.Description,
+		Path:        vars.TagsFilename,
+		Keywords:    keywords,
+		Description: description,
 		Tags:        make([]*Tag, 0, len(tags.Tags)),
 	}
 
@@ -67,9 +77,9 @@ func buildTags(conf *loader.Config, tags *loader.Tags, ps []*Post) (*Tags, error
 			keys = append(keys, t.Title)
 		}
 
-		p := path.Join(vars.TagsDir, t.Slug+vars.Ext)
+		p, permalink := buildPermalink(conf.URL, permalinks(conf).Tag, strings.NewReplacer(loader.PermalinkSlug, t.Slug), path.Join(vars.TagsDir, t.Slug+vars.Ext))
 		ts.Tags = append(ts.Tags, &Tag{
-			Permalink: BuildURL(conf.URL, p),
+			Permalink: permalink,
 			Slug:      t.Slug,
 			Path:      p,
 			Title:     t.Title,
@@ -82,10 +92,19 @@ func buildTags(conf *loader.Config, tags *loader.Tags, ps []*Post) (*Tags, error
 		ts.Keywords = strings.Join(keys, ",")
 	}
 
-	if err := ts.relationTagsPosts(ps); err != nil {
+	if err := ts.relationTagsPosts(ps, true); err != nil {
+		return nil, err
+	}
+	if err := ts.relationTagsPosts(unlisted, false); err != nil {
 		return nil, err
 	}
 	ts.clearTags() // 清除无文章关联的标签
+	for _, p := range unlisted {
+		p.Tags = sliceutil.Delete(p.Tags, func(t *Tag, _ int) bool { return len(t.Posts) == 0 })
+	}
+	for _, t := range ts.Tags {
+		t.WordCount, t.CharCount, t.ReadingTime = sumStats(t.Posts)
+	}
 	sortTags(ts.Tags, tags.OrderType, tags.Order)
 	tagsPrevNext(ts.Tags)
 
@@ -105,16 +124,21 @@ func sortTags(tags []*Tag, typ, order string) {
 }
 
 // 关联 tags 和 posts 的信息
-func (ts *Tags) relationTagsPosts(posts []*Post) error {
+//
+// listed 为 false 时，仅为文章关联标签，标签的文章列表以及时间不受影响。
+func (ts *Tags) relationTagsPosts(posts []*Post, listed bool) error {
 	for _, p := range posts {
 		for _, tag := range p.tags {
 			t := findTagByName(ts.Tags, tag)
 			if t == nil {
 				return &loader.FieldError{File: p.Slug, Message: localeutil.Phrase("not found"), Field: "tags." + tag}
 			}
-			t.Posts = append(t.Posts, p)
 			p.Tags = append(p.Tags, t)
+			if !listed {
+				continue
+			}
 
+			t.Posts = append(t.Posts, p)
 			if t.Created.Before(p.Created) {
 				t.Created = p.Created
 			}
@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package diff 提供按行比较文本的功能
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type opType int8
+
+const (
+	opEqual opType = iota
+	opDelete
+	opInsert
+)
+
+type edit struct {
+	op   opType
+	line string
+}
+
+// Unified 返回 unified 格式的比较结果
+//
+// oldName 和 newName 分别为比较双方在结果中显示的名称；
+// n 表示每一处变化前后保留的上下文行数。
+// 如果两者内容相同，返回空字符串。
+func Unified(oldName, newName string, old, new []byte, n int) string {
+	edits := myers(lines(string(old)), lines(string(new)))
+
+	buf := &strings.Builder{}
+	for i := 0; i < len(edits); {
+		if edits[i].op == opEqual {
+			i++
+			continue
+		}
+
+		// 找到当前 hunk 的最后一处变化，间隔小于等于 2n 的变化合并为一个 hunk。
+		last := i
+		for j := i + 1; j < len(edits) && j-last <= 2*n+1; j++ {
+			if edits[j].op != opEqual {
+				last = j
+			}
+		}
+
+		start := max(i-n, 0)
+		end := min(last+n+1, len(edits))
+
+		if buf.Len() == 0 {
+			fmt.Fprintf(buf, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		writeHunk(buf, edits, start, end)
+
+		i = end
+	}
+
+	return buf.String()
+}
+
+func writeHunk(buf *strings.Builder, edits []edit, start, end int) {
+	// 计算 start 之前的行数
+	var oldLine, newLine int
+	for _, e := range edits[:start] {
+		if e.op != opInsert {
+			oldLine++
+		}
+		if e.op != opDelete {
+			newLine++
+		}
+	}
+
+	var oldCount, newCount int
+	for _, e := range edits[start:end] {
+		if e.op != opInsert {
+			oldCount++
+		}
+		if e.op != opDelete {
+			newCount++
+		}
+	}
+
+	// 行数为 0 时，起始行号为变化之前的行号。
+	if oldCount > 0 {
+		oldLine++
+	}
+	if newCount > 0 {
+		newLine++
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+
+	for _, e := range edits[start:end] {
+		switch e.op {
+		case opEqual:
+			buf.WriteByte(' ')
+		case opDelete:
+			buf.WriteByte('-')
+		case opInsert:
+			buf.WriteByte('+')
+		}
+		buf.WriteString(e.line)
+		buf.WriteByte('\n')
+	}
+}
+
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// myers 算法计算从 a 到 b 的最短编辑过程
+//
+// http://www.xmailserver.org/diff2.pdf
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	trace := make([][]int, 0, 10)
+
+loop:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				break loop
+			}
+		}
+	}
+
+	// 从终点反向查找编辑过程
+	edits := make([]edit, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{op: opEqual, line: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{op: opInsert, line: b[y-1]})
+			} else {
+				edits = append(edits, edit{op: opDelete, line: a[x-1]})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
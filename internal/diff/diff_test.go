@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestMyers(t *testing.T) {
+	a := assert.New(t, false)
+
+	edits := myers([]string{"a", "b", "c", "a", "b", "b", "a"}, []string{"c", "b", "a", "b", "a", "c"})
+	var del, ins, eq int
+	for _, e := range edits {
+		switch e.op {
+		case opDelete:
+			del++
+		case opInsert:
+			ins++
+		case opEqual:
+			eq++
+		}
+	}
+	a.Equal(del+ins, 5).Equal(eq, 4)
+
+	a.Empty(myers(nil, nil))
+	a.Equal(myers(nil, []string{"a"}), []edit{{op: opInsert, line: "a"}})
+	a.Equal(myers([]string{"a"}, nil), []edit{{op: opDelete, line: "a"}})
+}
+
+func TestUnified(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Empty(Unified("a", "b", []byte("1\n2\n"), []byte("1\n2\n"), 3))
+
+	a.Equal(Unified("a", "b", []byte("1\n2\n3\n"), []byte("1\nx\n3\n"), 3), `--- a
++++ b
+@@ -1,3 +1,3 @@
+ 1
+-2
++x
+ 3
+`)
+
+	old := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	new := []byte("1\nx\n3\n4\n5\n6\n7\n8\n9\ny\n")
+	a.Equal(Unified("a", "b", old, new, 1), `--- a
++++ b
+@@ -1,3 +1,3 @@
+ 1
+-2
++x
+ 3
+@@ -9,2 +9,2 @@
+ 9
+-10
++y
+`)
+
+	// 间隔较小时合并为一个 hunk
+	a.Equal(Unified("a", "b", old, new, 4), `--- a
++++ b
+@@ -1,10 +1,10 @@
+ 1
+-2
++x
+ 3
+ 4
+ 5
+ 6
+ 7
+ 8
+ 9
+-10
++y
+`)
+
+	a.Equal(Unified("a", "b", nil, []byte("1\n"), 3), `--- a
++++ b
+@@ -0,0 +1,1 @@
++1
+`)
+}
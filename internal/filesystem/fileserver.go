@@ -12,14 +12,28 @@ import (
 	"net/http"
 	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/caixw/blogit/v2/internal/vars"
 )
 
+// 预压缩文件的后缀及对应的 Content-Encoding，按优先级排序。
+//
+// 与 compress 的配置相对应，目前只有 gzip。
+var encodings = []struct{ ext, name string }{
+	{ext: ".gz", name: "gzip"},
+}
+
 // FileServer 以 fsys 为根目录作为静态文件服务
 //
+// 如果存在同名的 .gz 文件且客户端的 Accept-Encoding 允许，
+// 会直接输出这些预压缩的内容，并设置 Content-Encoding 和 Vary 报头。
+//
+// redirect 用于查找文件 p 是否需要重定向，返回值不为空时，会以 301 跳转到该地址，
+// p 为相对于 fsys 的文件路径，redirect 可以为空；
 // erro 在出错时日志的输出通道，可以为空，表示输出到 log.Default()；
-func FileServer(fsys fs.FS, erro *log.Logger) http.Handler {
+func FileServer(fsys fs.FS, redirect func(p string) string, erro *log.Logger) http.Handler {
 	if erro == nil {
 		erro = log.Default()
 	}
@@ -45,7 +59,32 @@ func FileServer(fsys fs.FS, erro *log.Logger) http.Handler {
 			goto STAT
 		}
 
-		data, err := fs.ReadFile(fsys, p)
+		if redirect != nil {
+			if url := redirect(p); url != "" {
+				http.Redirect(w, r, url, http.StatusMovedPermanently)
+				return
+			}
+		}
+
+		name := p
+		vary := false
+		for _, enc := range encodings {
+			if _, err := fs.Stat(fsys, p+enc.ext); err != nil {
+				continue
+			}
+
+			vary = true
+			if acceptEncoding(r.Header.Get("Accept-Encoding"), enc.name) {
+				w.Header().Set("Content-Encoding", enc.name)
+				name = p + enc.ext
+				break
+			}
+		}
+		if vary {
+			w.Header().Add("Vary", "Accept-Encoding")
+		}
+
+		data, err := fs.ReadFile(fsys, name)
 		if err != nil {
 			printError(erro, err, w)
 			return
@@ -56,6 +95,32 @@ func FileServer(fsys fs.FS, erro *log.Logger) http.Handler {
 	})
 }
 
+// 判断 Accept-Encoding 报头 header 是否接受 enc 编码
+//
+// 明确指定的 enc 优先于 *，q=0 表示不接受。
+func acceptEncoding(header, enc string) bool {
+	wildcard := false
+	for _, item := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(item, ";")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case enc:
+			return acceptQuality(params)
+		case "*":
+			wildcard = acceptQuality(params)
+		}
+	}
+	return wildcard
+}
+
+func acceptQuality(params string) bool {
+	q, found := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+	if !found {
+		return true
+	}
+	v, err := strconv.ParseFloat(q, 64)
+	return err == nil && v > 0
+}
+
 func printError(erro *log.Logger, err error, w http.ResponseWriter) {
 	switch {
 	case errors.Is(err, fs.ErrPermission):
@@ -7,8 +7,10 @@ package filesystem
 import (
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"testing/fstest"
 
 	"github.com/issue9/assert/v4"
 	"github.com/issue9/assert/v4/rest"
@@ -17,7 +19,7 @@ import (
 func TestFileServer(t *testing.T) {
 	a := assert.New(t, false)
 
-	fs := FileServer(os.DirFS("./"), log.Default())
+	fs := FileServer(os.DirFS("./"), nil, log.Default())
 	a.NotNil(fs)
 	http.Handle("/assets/", http.StripPrefix("/assets/", fs))
 
@@ -45,3 +47,108 @@ func TestFileServer(t *testing.T) {
 	// 404
 	s.Get("/assets/not-exists").Do(nil).Status(http.StatusNotFound)
 }
+
+func TestFileServer_encoding(t *testing.T) {
+	a := assert.New(t, false)
+
+	fsys := fstest.MapFS{
+		"index.html":    &fstest.MapFile{Data: []byte("<html>")},
+		"index.html.gz": &fstest.MapFile{Data: []byte("gzip")},
+		"style.css":     &fstest.MapFile{Data: []byte("body{}")},
+		"style.css.gz":  &fstest.MapFile{Data: []byte("gzip")},
+		"style.css.br":  &fstest.MapFile{Data: []byte("br")},
+		"app.js":        &fstest.MapFile{Data: []byte("let a")},
+	}
+	s := FileServer(fsys, nil, log.Default())
+
+	get := func(p, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, p, nil)
+		if accept != "" {
+			r.Header.Set("Accept-Encoding", accept)
+		}
+		s.ServeHTTP(w, r)
+		a.Equal(w.Code, http.StatusOK)
+		return w
+	}
+
+	w := get("/", "gzip, deflate")
+	a.Equal(w.Body.String(), "gzip").
+		Equal(w.Header().Get("Content-Encoding"), "gzip").
+		Equal(w.Header().Get("Vary"), "Accept-Encoding").
+		Contains(w.Header().Get("Content-Type"), "text/html")
+
+	w = get("/index.html", "")
+	a.Equal(w.Body.String(), "<html>").
+		Empty(w.Header().Get("Content-Encoding")).
+		Equal(w.Header().Get("Vary"), "Accept-Encoding")
+
+	w = get("/index.html", "gzip;q=0, *")
+	a.Equal(w.Body.String(), "<html>").Empty(w.Header().Get("Content-Encoding"))
+
+	// 不支持 .br 文件
+	w = get("/style.css", "gzip, br")
+	a.Equal(w.Body.String(), "gzip").
+		Equal(w.Header().Get("Content-Encoding"), "gzip").
+		Contains(w.Header().Get("Content-Type"), "text/css")
+
+	w = get("/style.css", "br")
+	a.Equal(w.Body.String(), "body{}").Empty(w.Header().Get("Content-Encoding"))
+
+	w = get("/app.js", "gzip, br")
+	a.Equal(w.Body.String(), "let a").
+		Empty(w.Header().Get("Content-Encoding")).
+		Empty(w.Header().Get("Vary"))
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.True(acceptEncoding("gzip", "gzip")).
+		True(acceptEncoding("deflate, GZIP;q=0.5", "gzip")).
+		True(acceptEncoding("*", "gzip")).
+		False(acceptEncoding("", "gzip")).
+		False(acceptEncoding("gzip;q=0", "gzip")).
+		False(acceptEncoding("*, gzip;q=0", "gzip")).
+		False(acceptEncoding("deflate", "gzip"))
+}
+
+func TestFileServer_redirect(t *testing.T) {
+	a := assert.New(t, false)
+
+	fsys := fstest.MapFS{
+		"old/index.html":   &fstest.MapFile{Data: []byte("<html>old")},
+		"old/p1.html":      &fstest.MapFile{Data: []byte("<html>old p1")},
+		"posts/p1.html":    &fstest.MapFile{Data: []byte("<html>p1")},
+		"posts/index.html": &fstest.MapFile{Data: []byte("<html>posts")},
+	}
+	redirects := map[string]string{
+		"old/index.html": "https://example.com/posts/",
+		"old/p1.html":    "https://example.com/posts/p1.html",
+	}
+	s := FileServer(fsys, func(p string) string { return redirects[p] }, log.Default())
+
+	get := func(p string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, p, nil))
+		return w
+	}
+
+	w := get("/old/p1.html")
+	a.Equal(w.Code, http.StatusMovedPermanently).
+		Equal(w.Header().Get("Location"), "https://example.com/posts/p1.html")
+
+	w = get("/old/")
+	a.Equal(w.Code, http.StatusMovedPermanently).
+		Equal(w.Header().Get("Location"), "https://example.com/posts/")
+
+	w = get("/old")
+	a.Equal(w.Code, http.StatusMovedPermanently).
+		Equal(w.Header().Get("Location"), "https://example.com/posts/")
+
+	w = get("/posts/p1.html")
+	a.Equal(w.Code, http.StatusOK).Equal(w.Body.String(), "<html>p1")
+
+	w = get("/not-exists.html")
+	a.Equal(w.Code, http.StatusNotFound)
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Cache 文章解析结果的缓存
+//
+// 以文件路径为键名，记录了文件内容的 hash 值以及对应的解析结果，
+// 文件内容未改变的文章，不会再次经过 markdown 的解析。
+//
+// 可以通过 encoding/json 进行序列化，以便在多个进程之间共享。
+type Cache struct {
+	mux   sync.Mutex
+	Posts map[string]*CachedPost `json:"posts"`
+}
+
+// CachedPost 缓存的单篇文章
+type CachedPost struct {
+	Hash string `json:"hash"` // 文件内容的 hash 值
+	Post *Post  `json:"post"` // 未经 sanitize 处理的解析结果
+}
+
+// NewCache 声明 [Cache] 对象
+func NewCache() *Cache {
+	return &Cache{Posts: make(map[string]*CachedPost, 100)}
+}
+
+// 获取 path 对应的缓存内容，如果不存在或是 hash 不匹配，返回 nil。
+//
+// 返回的是缓存内容的副本，调用方可以随意修改。
+func (c *Cache) get(path, hash string) *Post {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if item, found := c.Posts[path]; found && item.Hash == hash {
+		return item.Post.clone()
+	}
+	return nil
+}
+
+// post 应该是未经 sanitize 处理的内容。
+//
+// 缓存的是 post 的副本，之后对 post 的修改不会影响缓存。
+func (c *Cache) set(path, hash string, post *Post) {
+	p := post.clone()
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.Posts == nil {
+		c.Posts = make(map[string]*CachedPost, 100)
+	}
+	c.Posts[path] = &CachedPost{Hash: hash, Post: p}
+}
+
+// 深度复制 p，sanitize 会修改其中的切片元素，不能与缓存共用。
+func (p *Post) clone() *Post {
+	post := *p
+	post.Tags = slices.Clone(p.Tags)
+	post.Aliases = slices.Clone(p.Aliases)
+	post.TOC = slices.Clone(p.TOC)
+
+	if p.Authors != nil {
+		post.Authors = make([]*Author, 0, len(p.Authors))
+		for _, a := range p.Authors {
+			if a != nil {
+				author := *a
+				a = &author
+			}
+			post.Authors = append(post.Authors, a)
+		}
+	}
+
+	if p.License != nil {
+		l := *p.License
+		post.License = &l
+	}
+
+	return &post
+}
+
+// 仅保留 dir 目录下 paths 中的缓存项，其它目录的缓存项不受影响。
+func (c *Cache) retain(dir string, paths []string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	exists := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		exists[p] = struct{}{}
+	}
+
+	for p := range c.Posts {
+		if !strings.HasPrefix(p, dir+"/") {
+			continue
+		}
+		if _, found := exists[p]; !found {
+			delete(c.Posts, p)
+		}
+	}
+}
+
+// Hash 计算内容的 hash 值
+func Hash(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
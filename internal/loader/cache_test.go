@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+func TestCache(t *testing.T) {
+	a := assert.New(t, false)
+	c := NewCache()
+
+	posts, err := LoadPosts(context.Background(), testdata.Source, false, c, 0)
+	a.NotError(err).Equal(3, len(posts)).
+		Equal(4, len(c.Posts)) // 草稿也会被缓存
+
+	// 缓存的是未经 sanitize 处理的内容
+	item := c.Posts["posts/2020/12/draft.md"]
+	a.NotNil(item).NotEmpty(item.Hash).Empty(item.Post.Slug)
+
+	// 从缓存中获取的是副本
+	p1 := c.get("posts/p1.md", c.Posts["posts/p1.md"].Hash)
+	a.NotNil(p1)
+	p1.Title = "changed"
+	a.Equal(c.Posts["posts/p1.md"].Post.Title, "p1")
+	p1.Tags[0] = "changed"
+	p1.Authors[0].Name = "changed"
+	a.NotEqual(c.Posts["posts/p1.md"].Post.Tags[0], "changed").
+		NotEqual(c.Posts["posts/p1.md"].Post.Authors[0].Name, "changed")
+
+	// 缓存之后对原对象的修改不影响缓存
+	p := &Post{Title: "p", Aliases: []string{"/old.html"}}
+	c.set("posts/p.md", "hash", p)
+	p.Aliases[0] = "old.html"
+	a.Equal(c.Posts["posts/p.md"].Post.Aliases, []string{"/old.html"})
+	delete(c.Posts, "posts/p.md")
+
+	// hash 不匹配
+	a.Nil(c.get("posts/p1.md", "hash"))
+
+	posts, err = LoadPosts(context.Background(), testdata.Source, true, c, 4)
+	a.NotError(err).Equal(4, len(posts))
+
+	pages, err := LoadPages(context.Background(), testdata.Source, true, c, 4)
+	a.NotError(err).Equal(1, len(pages)).
+		Equal(5, len(c.Posts))
+
+	c.retain(vars.PostsDir, []string{"posts/p1.md"})
+	a.Equal(2, len(c.Posts)).NotNil(c.Posts["pages/about.md"])
+}
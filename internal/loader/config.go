@@ -6,7 +6,9 @@ package loader
 
 import (
 	"io/fs"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/issue9/localeutil"
@@ -31,14 +33,78 @@ type Config struct {
 	Description string    `yaml:"description,omitempty"` // 所有页面默认情况下的 description
 	Menus       []*Link   `yaml:"menus,omitempty"`       // 菜单
 	TOC         int       `yaml:"toc,omitempty"`         // 当 headline 的数量大于此值时，生成 TOC
+	Excerpt     int       `yaml:"excerpt,omitempty"`     // 自动生成摘要时的最大字符数，为 0 表示采用默认值，小于 0 表示不自动生成。
+	Reading     *Reading  `yaml:"reading,omitempty"`     // 计算阅读时间所用的阅读速度，为空表示采用默认值。
 	Index       *Index    `yaml:"index"`                 // 分页设置
 
-	Archive *Archive `yaml:"archive,omitempty"`
-	RSS     *RSS     `yaml:"rss,omitempty"`
-	Atom    *RSS     `yaml:"atom,omitempty"`
-	Sitemap *Sitemap `yaml:"sitemap,omitempty"`
-	Robots  []*Agent `yaml:"robots,omitempty"`  // 不为空，表示托管 robots.txt 的生成
-	Profile *Profile `yaml:"profile,omitempty"` // 不为空，表示托管 README.md 的生成
+	Archive  *Archive  `yaml:"archive,omitempty"`
+	RSS      *RSS      `yaml:"rss,omitempty"`
+	Atom     *RSS      `yaml:"atom,omitempty"`
+	Sitemap  *Sitemap  `yaml:"sitemap,omitempty"`
+	Robots   []*Agent  `yaml:"robots,omitempty"`   // 不为空，表示托管 robots.txt 的生成
+	Profile  *Profile  `yaml:"profile,omitempty"`  // 不为空，表示托管 README.md 的生成
+	Minify   *Minify   `yaml:"minify,omitempty"`   // 不为空，表示对输出的内容进行压缩
+	Compress *Compress `yaml:"compress,omitempty"` // 不为空，表示为输出的内容生成预压缩的 .gz 文件
+
+	// 不为空，表示为主题中的 CSS 和 JS 文件以及代码高亮的 CSS 文件生成带内容哈希的文件名。
+	Fingerprint *Fingerprint `yaml:"fingerprint,omitempty"`
+
+	// 各类页面的地址格式，为空表示全部采用默认的地址。
+	Permalinks *Permalinks `yaml:"permalinks,omitempty"`
+
+	// 不为空，表示除了跳转页面之外，还需要为文章的旧地址生成服务端的重定向规则。
+	Redirects *Redirects `yaml:"redirects,omitempty"`
+
+	// 不为空，表示需要为每篇文章计算相关的文章。
+	Related *Related `yaml:"related,omitempty"`
+
+	// 除 Language 之外的其它语言，不为空表示这是一个多语言的站点。
+	Languages []*Language `yaml:"languages,omitempty"`
+}
+
+// Related 相关文章的配置项
+//
+// 相似度为标签的 Jaccard 系数与正文的 TF-IDF 余弦相似度按权重相加之和，
+// 权重为 0 表示不计算该项，两者都为 0 时，仅计算标签的相似度。
+type Related struct {
+	Size    int     `yaml:"size,omitempty"`    // 相关文章的最大数量，默认为 5。
+	Tags    float64 `yaml:"tags,omitempty"`    // 标签相似度的权重
+	Content float64 `yaml:"content,omitempty"` // 正文相似度的权重
+}
+
+// Redirects 重定向规则的配置项
+type Redirects struct {
+	Netlify bool `yaml:"netlify,omitempty"` // 生成 Netlify、Cloudflare Pages 等平台使用的 _redirects 文件
+	Nginx   bool `yaml:"nginx,omitempty"`   // 生成可供 nginx 的 map 指令使用的 redirects.map 文件
+}
+
+// Minify 压缩输出内容的配置项
+//
+// 每一项表示是否压缩对应类型的文件，主题的内容在压缩之后出错的，可以单独关闭该类型。
+type Minify struct {
+	HTML bool `yaml:"html,omitempty"` // 所有的 HTML 页面
+	CSS  bool `yaml:"css,omitempty"`  // 主题中的 CSS 以及生成的代码高亮 CSS
+	JS   bool `yaml:"js,omitempty"`   // 主题中的 JS
+	XML  bool `yaml:"xml,omitempty"`  // 生成的 RSS、Atom 和 Sitemap
+	JSON bool `yaml:"json,omitempty"` // 所有的 JSON 文件
+}
+
+// Compress 预压缩的配置项
+//
+// 对于符合条件的文件，会在同目录下生成添加了 .gz 后缀的压缩文件，
+// 可以配合 nginx 的 gzip_static 等功能使用。
+type Compress struct {
+	Size  int      `yaml:"size,omitempty"`  // 大于等于此值的文件才会被压缩，默认为 1024。
+	Types []string `yaml:"types,omitempty"` // 需要压缩的文件扩展名，默认为 DefaultCompressTypes。
+}
+
+// DefaultCompressTypes 默认需要预压缩的文件类型
+var DefaultCompressTypes = []string{".html", ".css", ".js", ".xml", ".xsl", ".json", ".svg", ".txt"}
+
+// Fingerprint 资源指纹的配置项
+type Fingerprint struct {
+	// 子资源完整性校验值的算法，可以是 sha256、sha384 和 sha512，为空表示不需要。
+	Integrity string `yaml:"integrity,omitempty"`
 }
 
 // RSS RSS 和 Atom 相关的配置项
@@ -119,6 +185,18 @@ func (conf *Config) sanitize() *FieldError {
 		return &FieldError{Message: GreatZero, Field: "toc", Value: conf.TOC}
 	}
 
+	if conf.Excerpt == 0 {
+		conf.Excerpt = DefaultExcerptSize
+	}
+
+	if conf.Reading == nil {
+		conf.Reading = &Reading{}
+	}
+	if err := conf.Reading.sanitize(); err != nil {
+		err.Field = "reading." + err.Field
+		return err
+	}
+
 	// index
 	if conf.Index == nil {
 		return &FieldError{Message: Required, Field: "index"}
@@ -186,6 +264,100 @@ func (conf *Config) sanitize() *FieldError {
 		}
 	}
 
+	// compress
+	if conf.Compress != nil {
+		if err := conf.Compress.sanitize(); err != nil {
+			err.Field = "compress." + err.Field
+			return err
+		}
+	}
+
+	// languages
+	for i, l := range conf.Languages {
+		if err := l.sanitize(conf); err != nil {
+			err.Field = "languages[" + strconv.Itoa(i) + "]." + err.Field
+			return err
+		}
+	}
+
+	// related
+	if conf.Related != nil {
+		if err := conf.Related.sanitize(); err != nil {
+			err.Field = "related." + err.Field
+			return err
+		}
+	}
+
+	// fingerprint
+	if conf.Fingerprint != nil {
+		if err := conf.Fingerprint.sanitize(); err != nil {
+			err.Field = "fingerprint." + err.Field
+			return err
+		}
+	}
+
+	// permalinks
+	if conf.Permalinks == nil {
+		conf.Permalinks = &Permalinks{}
+	}
+	if err := conf.Permalinks.sanitize(); err != nil {
+		err.Field = "permalinks." + err.Field
+		return err
+	}
+
+	return nil
+}
+
+func (f *Fingerprint) sanitize() *FieldError {
+	switch f.Integrity {
+	case "", "sha256", "sha384", "sha512":
+		return nil
+	default:
+		return &FieldError{Message: InvalidValue, Field: "integrity", Value: f.Integrity}
+	}
+}
+
+func (c *Compress) sanitize() *FieldError {
+	if c.Size < 0 {
+		return &FieldError{Message: InvalidValue, Field: "size", Value: c.Size}
+	} else if c.Size == 0 {
+		c.Size = 1024
+	}
+
+	if len(c.Types) == 0 {
+		c.Types = slices.Clone(DefaultCompressTypes)
+	}
+	for i, t := range c.Types {
+		if t == "" || t == "." {
+			return &FieldError{Message: InvalidValue, Field: "types[" + strconv.Itoa(i) + "]", Value: t}
+		}
+		t = strings.ToLower(t) // 文件的扩展名也以小写形式比较
+		if t[0] != '.' {
+			t = "." + t
+		}
+		c.Types[i] = t
+	}
+
+	return nil
+}
+
+func (r *Related) sanitize() *FieldError {
+	if r.Size < 0 {
+		return &FieldError{Message: GreatZero, Field: "size", Value: r.Size}
+	} else if r.Size == 0 {
+		r.Size = 5
+	}
+
+	if r.Tags < 0 {
+		return &FieldError{Message: InvalidValue, Field: "tags", Value: r.Tags}
+	}
+	if r.Content < 0 {
+		return &FieldError{Message: InvalidValue, Field: "content", Value: r.Content}
+	}
+	if r.Tags == 0 && r.Content == 0 {
+		r.Tags = 1
+	}
+
 	return nil
 }
 
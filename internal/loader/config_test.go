@@ -22,6 +22,8 @@ func TestLoadConfig(t *testing.T) {
 
 	a.Equal(conf.Author.Name, "author1")
 	a.Equal(conf.Language, "cmn-Hans")
+	a.NotNil(conf.Permalinks).Empty(conf.Permalinks.Post)
+	a.Equal(conf.Excerpt, DefaultExcerptSize)
 
 	conf, err = LoadConfig(testdata.Source, "not-exists.yaml")
 	a.ErrorIs(err, fs.ErrNotExist).Nil(conf)
@@ -90,3 +92,56 @@ func TestIndex_sanitize(t *testing.T) {
 	i.Title = "xx"
 	a.NotError(i.sanitize())
 }
+
+func TestCompress_sanitize(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := &Compress{}
+	a.NotError(c.sanitize()).
+		Equal(c.Size, 1024).
+		Equal(c.Types, DefaultCompressTypes)
+
+	c = &Compress{Size: -1}
+	err := c.sanitize()
+	a.Equal(err.Field, "size")
+
+	c = &Compress{Types: []string{"css", ".js", ".HTML", "Xml"}}
+	a.NotError(c.sanitize()).
+		Equal(c.Types, []string{".css", ".js", ".html", ".xml"})
+
+	c = &Compress{Types: []string{"css", "."}}
+	err = c.sanitize()
+	a.Equal(err.Field, "types[1]")
+}
+
+func TestFingerprint_sanitize(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.NotError((&Fingerprint{}).sanitize()).
+		NotError((&Fingerprint{Integrity: "sha384"}).sanitize())
+
+	err := (&Fingerprint{Integrity: "md5"}).sanitize()
+	a.Equal(err.Field, "integrity")
+}
+
+func TestRelated_sanitize(t *testing.T) {
+	a := assert.New(t, false)
+
+	r := &Related{}
+	a.NotError(r.sanitize()).
+		Equal(r.Size, 5).
+		Equal(r.Tags, 1.0).
+		Equal(r.Content, 0.0)
+
+	r = &Related{Size: 3, Content: 0.5}
+	a.NotError(r.sanitize()).
+		Equal(r.Size, 3).
+		Equal(r.Tags, 0.0).
+		Equal(r.Content, 0.5)
+
+	err := (&Related{Size: -1}).sanitize()
+	a.Equal(err.Field, "size")
+
+	err = (&Related{Content: -1}).sanitize()
+	a.Equal(err.Field, "content")
+}
@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// DefaultExcerptSize 自动生成摘要时默认的最大字符数
+const DefaultExcerptSize = 150
+
+// 分隔摘要与正文的标记
+//
+// 需要单独占一行，此时 markdown 会将其作为 HTML 块原样输出，
+// 而位于段落中的标记不会被匹配。
+var moreExpr = regexp.MustCompile(`(?m)^<!--\s*more\s*-->$`)
+
+// 截取 HTML 内容中 <!--more--> 之前的部分，没有该标记时返回空值。
+func cutMore(content string) string {
+	if loc := moreExpr.FindStringIndex(content); loc != nil {
+		return strings.TrimSpace(content[:loc[0]])
+	}
+	return ""
+}
+
+// 提取文档中的纯文本
+//
+// 代码块、HTML 以及脚注都会被忽略，连续的空白字符会被合并成一个空格，
+// 中日文字符之间的换行则会被去掉。源码中的 HTML 实体会被解码，
+// 返回的是未转义的纯文本，输出到 HTML 时需要再转义。
+func plainText(doc ast.Node, source []byte) string {
+	buf := &strings.Builder{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch n.Kind() {
+		case ast.KindCodeBlock, ast.KindFencedCodeBlock, ast.KindHTMLBlock, ast.KindRawHTML,
+			east.KindFootnoteList, east.KindFootnoteLink, east.KindFootnoteBacklink:
+			return ast.WalkSkipChildren, nil
+		}
+
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				buf.WriteByte('\n')
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch t := n.(type) {
+		case *ast.Text:
+			buf.WriteString(html.UnescapeString(string(t.Segment.Value(source))))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				buf.WriteByte('\n')
+			}
+		case *ast.String:
+			buf.WriteString(html.UnescapeString(string(t.Value)))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return normalizeSpace(buf.String())
+}
+
+// 合并 s 中的空白字符
+//
+// 中日文字符之间的空白会被去掉，其它的合并成一个空格。
+func normalizeSpace(s string) string {
+	buf := &strings.Builder{}
+	buf.Grow(len(s))
+
+	var prev rune
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+
+		if space && prev != 0 && !(isCJK(prev) && isCJK(r)) {
+			buf.WriteByte(' ')
+		}
+		buf.WriteRune(r)
+		prev = r
+		space = false
+	}
+
+	return buf.String()
+}
+
+// 是否为中日文的文字或是标点
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0x3000 && r <= 0x303f) || // CJK 标点
+		(r >= 0xff00 && r <= 0xffef) // 全角字符
+}
+
+// Excerpt 从纯文本 text 中截取最多 size 个字符作为摘要
+//
+// 以字符而不是字节计算长度，截断的位置如果在英文单词的中间，会退回到单词之前，
+// 被截断的内容会以 … 结尾。如果退回之后没有任何内容，即前 size 个字符属于同一个单词，
+// 则直接在第 size 个字符处截断单词，以保证摘要的长度不超过 size。
+func Excerpt(text string, size int) string {
+	if utf8.RuneCountInString(text) <= size {
+		return text
+	}
+
+	runes := []rune(text)
+	excerpt := trimExcerpt(runes[:size])
+	if isWordRune(runes[size-1]) && isWordRune(runes[size]) {
+		i := size - 1
+		for i > 0 && isWordRune(runes[i-1]) {
+			i--
+		}
+		if s := trimExcerpt(runes[:i]); s != "" {
+			excerpt = s
+		}
+	}
+
+	return excerpt + "…"
+}
+
+// 去掉摘要尾部的空格和标点
+func trimExcerpt(runes []rune) string {
+	return strings.TrimRightFunc(string(runes), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+}
+
+// 组成英文单词的字符
+func isWordRune(r rune) bool {
+	return !isCJK(r) && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '-')
+}
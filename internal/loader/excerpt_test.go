@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestConvert_excerpt(t *testing.T) {
+	a := assert.New(t, false)
+
+	p, err := convert([]byte("---\ntitle: t\n---\n# 标题\n\n第一段\n中文。\n\n<!--more-->\n\n```go\nfunc main() {}\n```\n\nsecond `code` line\n"))
+	a.NotError(err).NotNil(p)
+	a.Equal(p.Excerpt, `<h1 id="biao-ti">标题</h1>`+"\n<p>第一段\n中文。</p>").
+		Equal(p.Text, "标题第一段中文。 second code line")
+
+	// 段落中的标记不起作用
+	p, err = convert([]byte("---\ntitle: t\n---\nabc <!--more--> def\n"))
+	a.NotError(err).NotNil(p)
+	a.Empty(p.Excerpt).Equal(p.Text, "abc def")
+
+	// HTML 实体
+	p, err = convert([]byte("---\ntitle: t\n---\nTom &amp; Jerry &copy; 2024\n"))
+	a.NotError(err).NotNil(p)
+	a.Equal(p.Text, "Tom & Jerry © 2024").
+		Equal(Excerpt(p.Text, 8), "Tom…").
+		Equal(Excerpt(p.Text, 12), "Tom & Jerry…")
+}
+
+func TestExcerpt(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(Excerpt("abc def", 10), "abc def").
+		Equal(Excerpt("abc defghi", 6), "abc…").
+		Equal(Excerpt("abc, def", 5), "abc…").
+		Equal(Excerpt("abcdefghi", 5), "abcde…").
+		Equal(Excerpt("abcdefghi jkl", 5), "abcde…"). // 开头的单词超过 size，直接截断
+		Equal(Excerpt("(abcdefghi) jkl", 5), "(abcd…").
+		Equal(Excerpt("中文内容，很长的内容", 5), "中文内容…").
+		Equal(Excerpt("中文 abc def", 5), "中文…").
+		Equal(Excerpt("中文abc", 3), "中文…")
+}
+
+func TestNormalizeSpace(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(normalizeSpace("  abc \n def  "), "abc def").
+		Equal(normalizeSpace("中文\n内容 abc\n中文"), "中文内容 abc 中文")
+}
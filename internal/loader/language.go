@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"io/fs"
+	"strings"
+
+	"github.com/issue9/sliceutil"
+)
+
+// Language 多语言站点中除默认语言之外的语言
+//
+// 文章的 language 与 ID 相同的，属于该语言，其它的文章都属于默认语言。
+// 每种语言都有各自的索引页、标签页、存档页以及订阅，默认语言的在根目录下，其它语言的在 Path 目录下。
+type Language struct {
+	ID   string `yaml:"id"`             // 语言 ID，不能与 Config.Language 相同。
+	Path string `yaml:"path,omitempty"` // 该语言的页面所在的目录，默认为 ID 的小写形式。
+
+	// 以下内容为空时，采用 conf.yaml 中对应的值。
+	Title       string `yaml:"title,omitempty"`
+	Subtitle    string `yaml:"subtitle,omitempty"`
+	Keywords    string `yaml:"keywords,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+func (l *Language) sanitize(conf *Config) *FieldError {
+	if l.ID == "" {
+		return &FieldError{Message: Required, Field: "id"}
+	}
+	if l.ID == conf.Language || sliceutil.Count(conf.Languages, func(i *Language, _ int) bool { return i.ID == l.ID }) > 1 {
+		return &FieldError{Message: DupValue, Field: "id", Value: l.ID}
+	}
+
+	if l.Path == "" {
+		l.Path = strings.ToLower(l.ID)
+	}
+	l.Path = strings.Trim(l.Path, "/")
+	if !fs.ValidPath(l.Path) || l.Path == "." || isReservedPage(l.Path) {
+		return &FieldError{Message: InvalidValue, Field: "path", Value: l.Path}
+	}
+	if sliceutil.Count(conf.Languages, func(i *Language, _ int) bool { return i.Path == l.Path }) > 1 {
+		return &FieldError{Message: DupValue, Field: "path", Value: l.Path}
+	}
+
+	return nil
+}
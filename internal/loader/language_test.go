@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestLanguage_sanitize(t *testing.T) {
+	a := assert.New(t, false)
+
+	l := &Language{ID: "en-US"}
+	conf := &Config{Language: "cmn-Hans", Languages: []*Language{l}}
+	a.NotError(l.sanitize(conf)).Equal(l.Path, "en-us")
+
+	l = &Language{ID: "en", Path: "/english/"}
+	conf = &Config{Language: "cmn-Hans", Languages: []*Language{l}}
+	a.NotError(l.sanitize(conf)).Equal(l.Path, "english")
+
+	err := (&Language{}).sanitize(conf)
+	a.Equal(err.Field, "id")
+
+	// 与默认语言相同
+	err = (&Language{ID: "cmn-Hans"}).sanitize(conf)
+	a.Equal(err.Field, "id").Equal(err.Message, DupValue)
+
+	// 保留的目录
+	err = (&Language{ID: "en", Path: "tags"}).sanitize(conf)
+	a.Equal(err.Field, "path")
+
+	// 重复的目录
+	conf = &Config{Language: "cmn-Hans", Languages: []*Language{{ID: "en", Path: "en"}, {ID: "en-US", Path: "en"}}}
+	err = conf.Languages[0].sanitize(conf)
+	a.Equal(err.Field, "path").Equal(err.Message, DupValue)
+}
@@ -6,7 +6,6 @@ package loader
 
 import (
 	"bytes"
-	"io/fs"
 
 	fh "github.com/alecthomas/chroma/v2/formatters/html"
 	toc "github.com/mdigger/goldmark-toc"
@@ -47,18 +46,13 @@ var markdown = goldmark.New(
 	),
 )
 
-func convert(f fs.FS, path string) (*Post, error) {
-	bs, err := fs.ReadFile(f, path)
-	if err != nil {
-		return nil, err
-	}
-
+func convert(bs []byte) (*Post, error) {
 	ctx := parser.NewContext(parser.WithIDs(toc.NewIDs("")))
 	buf := new(bytes.Buffer)
 
 	doc := markdown.Parser().Parse(text.NewReader(bs), parser.WithContext(ctx))
 	headers := toc.Headers(doc, bs)
-	if err = markdown.Renderer().Render(buf, bs, doc); err != nil {
+	if err := markdown.Renderer().Render(buf, bs, doc); err != nil {
 		return nil, err
 	}
 
@@ -71,6 +65,9 @@ func convert(f fs.FS, path string) (*Post, error) {
 		return nil, err
 	}
 	post.Content = buf.String()
+	post.Excerpt = cutMore(post.Content)
+	post.Text = plainText(doc, bs)
+	post.Stats = countText(post.Text)
 
 	start := 6
 	for _, h := range headers {
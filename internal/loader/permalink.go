@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"io/fs"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/issue9/localeutil"
+
+	"github.com/caixw/blogit/v2/internal/vars"
+)
+
+// 地址格式中可用的占位符
+const (
+	PermalinkYear  = ":year"  // 文章创建时间的年份
+	PermalinkMonth = ":month" // 文章创建时间的月份，两位数字。
+	PermalinkDay   = ":day"   // 文章创建时间的日期，两位数字。
+	PermalinkSlug  = ":slug"  // 文章 slug 的最后一部分，在其它类型的页面中表示其唯一名称。
+	PermalinkPath  = ":path"  // 文章的 slug 去掉 posts/ 之后的部分
+	PermalinkPage  = ":page"  // 索引页的页码
+)
+
+var permalinkVarExpr = regexp.MustCompile(`:[a-z]+`)
+
+// Permalinks 自定义各类页面的地址格式
+//
+// 为空表示采用默认的地址。以 / 结尾的表示目录形式的地址，实际生成的是该目录下的 index.html，
+// 否则必须以 .html 结尾。比如 /:year/:month/:slug/ 会生成 2024/01/my-post/index.html。
+type Permalinks struct {
+	Post   string `yaml:"post,omitempty"`   // 文章，可以使用 :year、:month、:day、:slug 和 :path。
+	Page   string `yaml:"page,omitempty"`   // 独立页面，可以使用 :slug，表示去掉 pages/ 之后的部分。
+	Tag    string `yaml:"tag,omitempty"`    // 标签，可以使用 :slug。
+	Series string `yaml:"series,omitempty"` // 系列，可以使用 :slug。
+	Index  string `yaml:"index,omitempty"`  // 除首页之外的索引页，可以使用 :page。
+}
+
+func (p *Permalinks) sanitize() *FieldError {
+	postVars := []string{PermalinkYear, PermalinkMonth, PermalinkDay, PermalinkSlug, PermalinkPath}
+	slugVars := []string{PermalinkSlug}
+
+	items := []struct {
+		field    string
+		pattern  *string
+		vars     []string
+		required []string // 至少需要包含其中之一，以保证地址的唯一性。
+	}{
+		{field: "post", pattern: &p.Post, vars: postVars, required: []string{PermalinkSlug, PermalinkPath}},
+		{field: "page", pattern: &p.Page, vars: slugVars, required: slugVars},
+		{field: "tag", pattern: &p.Tag, vars: slugVars, required: slugVars},
+		{field: "series", pattern: &p.Series, vars: slugVars, required: slugVars},
+		{field: "index", pattern: &p.Index, vars: []string{PermalinkPage}, required: []string{PermalinkPage}},
+	}
+
+	for _, item := range items {
+		if *item.pattern == "" {
+			continue
+		}
+
+		pattern := strings.TrimPrefix(*item.pattern, "/")
+		if err := sanitizePermalink(pattern, item.vars, item.required); err != nil {
+			err.Field = item.field
+			err.Value = *item.pattern
+			return err
+		}
+		*item.pattern = pattern
+	}
+
+	return nil
+}
+
+func sanitizePermalink(pattern string, vs, required []string) *FieldError {
+	if !strings.HasSuffix(pattern, "/") && !strings.HasSuffix(pattern, vars.Ext) {
+		return &FieldError{Message: localeutil.Phrase("must end with / or %s", vars.Ext)}
+	}
+	if !fs.ValidPath(strings.TrimSuffix(pattern, "/")) {
+		return &FieldError{Message: InvalidValue}
+	}
+
+	found := false
+	for _, v := range permalinkVarExpr.FindAllString(pattern, -1) {
+		if !slices.Contains(vs, v) {
+			return &FieldError{Message: InvalidValue}
+		}
+		found = found || slices.Contains(required, v)
+	}
+	if !found {
+		return &FieldError{Message: localeutil.Phrase("must contain one of %s", strings.Join(required, ","))}
+	}
+
+	return nil
+}
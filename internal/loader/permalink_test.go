@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestPermalinks_sanitize(t *testing.T) {
+	a := assert.New(t, false)
+
+	p := &Permalinks{}
+	a.NotError(p.sanitize())
+
+	p = &Permalinks{
+		Post:   "/:year/:month/:day/:slug/",
+		Page:   "/:slug/",
+		Tag:    "/tag/:slug.html",
+		Series: "series/:slug/",
+		Index:  "/page/:page/",
+	}
+	a.NotError(p.sanitize()).
+		Equal(p.Post, ":year/:month/:day/:slug/").
+		Equal(p.Page, ":slug/").
+		Equal(p.Tag, "tag/:slug.html").
+		Equal(p.Series, "series/:slug/").
+		Equal(p.Index, "page/:page/")
+
+	// 缺少唯一性的占位符
+	p = &Permalinks{Post: "/:year/:month/"}
+	err := p.sanitize()
+	a.Equal(err.Field, "post").Equal(err.Value, "/:year/:month/")
+
+	// 无效的占位符
+	p = &Permalinks{Tag: "/tag/:year/:slug/"}
+	a.Equal(p.sanitize().Field, "tag")
+
+	// 无效的后缀
+	p = &Permalinks{Index: "/page/:page"}
+	a.Equal(p.sanitize().Field, "index")
+
+	// 无效的路径
+	p = &Permalinks{Series: "/series/../:slug/"}
+	a.Equal(p.sanitize().Field, "series")
+}
@@ -5,6 +5,8 @@
 package loader
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"path"
@@ -16,15 +18,17 @@ import (
 	"github.com/issue9/localeutil"
 	"github.com/issue9/sliceutil"
 
+	"github.com/caixw/blogit/v2/internal/pool"
 	"github.com/caixw/blogit/v2/internal/vars"
 )
 
 // 表示 Post.State 的各类值
 const (
-	StateTop     = "top"   // 置顶
-	StateLast    = "last"  // 放在尾部
-	StateDraft   = "draft" // 表示为草稿，不会加载此条数据
-	StateDefault = ""      // 默认值
+	StateTop      = "top"      // 置顶
+	StateLast     = "last"     // 放在尾部
+	StateDraft    = "draft"    // 表示为草稿，不会加载此条数据
+	StateUnlisted = "unlisted" // 不公开的文章，仅生成文章页面，不会出现在索引、标签、存档、订阅和 sitemap 中。
+	StateDefault  = ""         // 默认值
 )
 
 // Post 表示文章的信息
@@ -32,6 +36,7 @@ type Post struct {
 	Title    string    `yaml:"title"`
 	Created  time.Time `yaml:"created"`           // 创建时间
 	Modified time.Time `yaml:"modified"`          // 修改时间
+	Expires  time.Time `yaml:"expires,omitempty"` // 过期时间，过期之后不再发布，零值表示永不过期。
 	Summary  string    `yaml:"summary,omitempty"` // 摘要，同时也作为 meta.description 的内容
 
 	// 关联的标签列表
@@ -39,16 +44,31 @@ type Post struct {
 	// 标签名为各个标签的 slug 值，可以保证其唯一。
 	Tags []string `yaml:"tags"`
 
-	// State 表示文章的状态，有以下四种值：
+	// 所属的系列，为系列的 slug 值，可以为空。
+	Series string `yaml:"series,omitempty"`
+
+	// 在系列中的顺序，值小的排在前面。
+	//
+	// 为 0 表示未指定，这些文章按创建时间排在指定了顺序的文章之后。
+	SeriesOrder int `yaml:"seriesOrder,omitempty"`
+
+	// State 表示文章的状态，有以下五种值：
 	// - top 表示文章被置顶；
 	// - last 表示文章会被放置在最后；
 	// - draft 表示这是一篇草稿；
+	// - unlisted 表示不公开的文章，只能通过链接访问；
 	// - 空值 按默认的方式进行处理。
 	State string `yaml:"state,omitempty"`
 
 	// 封面地址，可以为空。
 	Image string `yaml:"image,omitempty"`
 
+	// 文章的旧地址
+	//
+	// 为相对于网站根目录的路径，会在这些地址上生成跳转到当前文章的页面。
+	// 以 / 结尾或是没有扩展名的表示目录，实际生成的是该目录下的 index.html。
+	Aliases []string `yaml:"aliases,omitempty"`
+
 	// 自定义 JSON-LD 数据
 	//
 	// 不需要包含 <script> 标签，只需要返回 JSON 格式数据好可。
@@ -62,9 +82,23 @@ type Post struct {
 	Language string    `yaml:"language,omitempty"`
 	Keywords string    `yaml:"keywords,omitempty"`
 
+	// 用于关联不同语言的译文
+	//
+	// 值相同而语言不同的文章互为译文，为空表示没有译文。
+	TranslationKey string `yaml:"translationKey,omitempty"`
+
 	Content string   `yaml:"-"` // markdown 内容
+	Excerpt string   `yaml:"-"` // 正文中 <!--more--> 之前的内容，没有该标记时为空。
+	Text    string   `yaml:"-"` // 正文的纯文本，不包含代码和 HTML。
+	Stats   Stats    `yaml:"-"` // 正文的字数统计
 	Slug    string   `yaml:"-"`
 	TOC     []Header `yaml:"-"`
+
+	// 是否为页面包
+	//
+	// 以 index.md 命名的文章为页面包，所在目录中的其它文件为该文章的资源，
+	// 生成的 HTML 文件也会放在该目录下。
+	Bundle bool `yaml:"-"`
 }
 
 // Header TOC 的每一项内容
@@ -77,10 +111,31 @@ type Header struct {
 
 // LoadPosts 加载所有的文章
 //
-// preview 模式下会加载草稿。
-func LoadPosts(f fs.FS, preview bool) ([]*Post, error) {
+// ctx 可用于取消加载过程；
+// preview 模式下会加载草稿；
+// c 为文章的解析缓存，可以为空，表示不需要缓存；
+// n 表示同时解析文章的最大数量，小于等于 1 表示按顺序解析。
+func LoadPosts(ctx context.Context, f fs.FS, preview bool, c *Cache, n int) ([]*Post, error) {
+	return loadPosts(ctx, f, vars.PostsDir, preview, c, n)
+}
+
+// LoadPages 加载所有的独立页面
+//
+// 独立页面与文章采用相同的格式，但是标签不是必须的。参数与 [LoadPosts] 相同。
+// pages 目录不存在时返回空值。
+func LoadPages(ctx context.Context, f fs.FS, preview bool, c *Cache, n int) ([]*Post, error) {
+	if _, err := fs.Stat(f, vars.PagesDir); errors.Is(err, fs.ErrNotExist) {
+		if c != nil {
+			c.retain(vars.PagesDir, nil)
+		}
+		return nil, nil
+	}
+	return loadPosts(ctx, f, vars.PagesDir, preview, c, n)
+}
+
+func loadPosts(ctx context.Context, f fs.FS, dir string, preview bool, c *Cache, n int) ([]*Post, error) {
 	paths := make([]string, 0, 10)
-	err := fs.WalkDir(f, vars.PostsDir, func(p string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(f, dir, func(p string, d fs.DirEntry, err error) error {
 		if err == nil && !d.IsDir() && strings.ToLower(path.Ext(p)) == vars.MarkdownExt {
 			paths = append(paths, p)
 		}
@@ -90,48 +145,71 @@ func LoadPosts(f fs.FS, preview bool) ([]*Post, error) {
 		return nil, err
 	}
 
+	if c != nil {
+		c.retain(dir, paths)
+	}
+
 	if len(paths) == 0 {
 		return nil, nil
 	}
 
-	posts := make([]*Post, 0, len(paths))
+	loaded := make([]*Post, len(paths))
+	err = pool.Run(ctx, n, len(paths), func(i int) (err error) {
+		loaded[i], err = loadPost(f, dir, paths[i], c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	for _, p := range paths {
-		post, err := loadPost(f, p)
-		if err != nil {
-			return nil, err
-		}
+	posts := make([]*Post, 0, len(paths))
+	for _, post := range loaded {
 		if preview || post.State != StateDraft {
 			posts = append(posts, post)
 		}
 	}
 
 	for _, p := range posts {
-		cnt := sliceutil.Count(posts, func(i *Post, _ int) bool {
-			return p.Slug == i.Slug && p.Slug != i.Slug
-		})
-		if cnt > 1 {
-			return nil, &FieldError{Message: DupValue, Field: "slug"}
+		cnt := sliceutil.Count(posts, func(i *Post, _ int) bool { return p.Slug == i.Slug })
+		if cnt > 1 { // 比如同时存在 posts/p1.md 和 posts/p1/index.md
+			return nil, &FieldError{Message: DupValue, Field: "slug", Value: p.Slug}
 		}
 	}
 
 	return posts, nil
 }
 
-func loadPost(f fs.FS, path string) (*Post, error) {
-	post, err := convert(f, path)
+func loadPost(f fs.FS, dir, path string, c *Cache) (*Post, error) {
+	bs, err := fs.ReadFile(f, path)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := post.sanitize(path); err != nil {
+	var post *Post
+	var hash string
+	if c != nil {
+		hash = Hash(bs)
+		post = c.get(path, hash)
+	}
+
+	if post == nil {
+		if post, err = convert(bs); err != nil {
+			return nil, err
+		}
+		if c != nil {
+			c.set(path, hash, post)
+		}
+	}
+
+	if err := post.sanitize(dir, path); err != nil {
 		err.File = path
 		return nil, err
 	}
 	return post, nil
 }
 
-func (p *Post) sanitize(path string) *FieldError {
+// dir 表示文章所在的目录，可以是 vars.PostsDir 或是 vars.PagesDir。
+func (p *Post) sanitize(dir, path string) *FieldError {
 	if p.Title == "" {
 		return &FieldError{Field: "title", Message: Required}
 	}
@@ -140,28 +218,49 @@ func (p *Post) sanitize(path string) *FieldError {
 	}
 
 	slug := Slug(path)
-	if strings.HasSuffix(strings.ToLower(slug[len(slug)-3:]), vars.MarkdownExt) {
-		slug = slug[:len(slug)-len(vars.MarkdownExt)] // 不能用 strings.TrimSuffix，后缀名可能是大写的
-	}
 	if strings.IndexFunc(slug, func(r rune) bool { return unicode.IsSpace(r) }) >= 0 {
 		return &FieldError{Field: "slug", Message: localeutil.StringPhrase("can not contain spaces"), Value: slug}
 	}
-	if !strings.HasPrefix(slug, vars.PostsDir+"/") {
-		return &FieldError{Field: "slug", Message: localeutil.Phrase("post must in %s", vars.PostsDir), Value: slug}
+	if !strings.HasPrefix(slug, dir+"/") {
+		return &FieldError{Field: "slug", Message: localeutil.Phrase("post must in %s", dir), Value: slug}
+	}
+	if dir == vars.PagesDir && isReservedPage(strings.TrimPrefix(slug, dir+"/")) {
+		return &FieldError{Field: "slug", Message: DupValue, Value: slug}
 	}
 	p.Slug = slug
+	p.Bundle = isBundle(path)
 
-	if len(p.Tags) == 0 {
+	if len(p.Tags) == 0 && dir == vars.PostsDir {
 		return &FieldError{Field: "tags", Message: Required}
 	}
 
+	if p.SeriesOrder < 0 {
+		return &FieldError{Field: "seriesOrder", Message: InvalidValue, Value: p.SeriesOrder}
+	}
+
+	if !p.Expires.IsZero() && !p.Expires.After(p.Created) {
+		return &FieldError{Field: "expires", Message: InvalidValue, Value: p.Expires}
+	}
+
+	for i, alias := range p.Aliases {
+		alias = strings.TrimPrefix(alias, "/")
+		if a := strings.TrimSuffix(alias, "/"); a == "" || !fs.ValidPath(a) {
+			return &FieldError{Field: "aliases[" + strconv.Itoa(i) + "]", Message: InvalidValue, Value: p.Aliases[i]}
+		}
+		p.Aliases[i] = alias
+	}
+
 	// state
-	if p.State != StateDefault && p.State != StateLast && p.State != StateTop && p.State != StateDraft {
+	switch p.State {
+	case StateDefault, StateLast, StateTop, StateDraft, StateUnlisted:
+	default:
 		return &FieldError{Message: InvalidValue, Field: "state", Value: p.State}
 	}
 
 	// template
-	if p.Template == "" {
+	if p.Template == "" && dir == vars.PagesDir {
+		p.Template = vars.PageTemplate
+	} else if p.Template == "" {
 		p.Template = vars.DefaultTemplate
 	}
 
@@ -182,10 +281,72 @@ func (p *Post) sanitize(path string) *FieldError {
 	return nil
 }
 
+// Schedule 过滤掉在 now 时刻尚未到发布时间以及已经过期的文章
+//
+// 创建时间晚于 now 的文章为定时发布的文章。
+// 返回值 next 为 now 之后第一次有文章发布或是过期的时间，零值表示没有。
+func Schedule(posts []*Post, now time.Time) (published []*Post, next time.Time) {
+	earlier := func(t time.Time) {
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+
+	published = make([]*Post, 0, len(posts))
+	for _, p := range posts {
+		switch {
+		case p.Created.After(now):
+			earlier(p.Created)
+		case !p.Expires.IsZero() && !p.Expires.After(now):
+		default:
+			published = append(published, p)
+			if !p.Expires.IsZero() {
+				earlier(p.Expires)
+			}
+		}
+	}
+
+	return published, next
+}
+
+// 独立页面输出在根目录下，name 是否会与程序生成的其它文件冲突。
+func isReservedPage(name string) bool {
+	switch first, _, _ := strings.Cut(name, "/"); first {
+	case vars.PostsDir, vars.TagsDir, vars.SeriesDir, vars.ThemesDir:
+		return true
+	}
+
+	switch file := name + vars.Ext; file {
+	case vars.IndexFilename, vars.TagsFilename, vars.ArchiveFilename:
+		return true
+	default:
+		var index int
+		_, err := fmt.Sscanf(file, vars.IndexFilenameFormat, &index)
+		return err == nil
+	}
+}
+
 // Slug 根据文章路径返回文章的唯一 ID
+//
+// markdown 文件会去掉后缀名，如果是页面包，则为其所在的目录，
+// 比如 posts/2024/my-post/index.md 的返回值为 posts/2024/my-post。
+// 其它文件则原样返回。
 func Slug(p string) string {
 	if !fs.ValidPath(p) {
 		panic(fmt.Sprintf("无效的参数 p: %s", p))
 	}
-	return strings.TrimLeft(p, "./")
+	p = strings.TrimLeft(p, "./")
+
+	if ext := path.Ext(p); strings.EqualFold(ext, vars.MarkdownExt) { // 后缀名可能是大写的
+		if isBundle(p) {
+			return path.Dir(p)
+		}
+		p = p[:len(p)-len(ext)]
+	}
+	return p
+}
+
+// p 是否为页面包中的文章
+func isBundle(p string) bool {
+	return path.Dir(p) != "." && strings.EqualFold(path.Base(p), vars.BundleIndex+vars.MarkdownExt)
 }
@@ -5,31 +5,97 @@
 package loader
 
 import (
+	"context"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/issue9/assert/v4"
 
 	"github.com/caixw/blogit/v2/internal/testdata"
+	"github.com/caixw/blogit/v2/internal/vars"
 )
 
 func TestLoadPosts(t *testing.T) {
 	a := assert.New(t, false)
 
-	posts, err := LoadPosts(testdata.Source, false)
+	posts, err := LoadPosts(context.Background(), testdata.Source, false, nil, 0)
 	a.NotError(err).Equal(3, len(posts))
 
-	posts, err = LoadPosts(testdata.Source, true)
+	posts, err = LoadPosts(context.Background(), testdata.Source, true, nil, 4)
 	a.NotError(err).Equal(4, len(posts))
 }
 
+func TestLoadPages(t *testing.T) {
+	a := assert.New(t, false)
+
+	pages, err := LoadPages(context.Background(), testdata.Source, false, nil, 0)
+	a.NotError(err).Length(pages, 1)
+	a.Equal(pages[0].Slug, "pages/about").
+		Equal(pages[0].Template, vars.PageTemplate).
+		Empty(pages[0].Tags)
+
+	// 不存在 pages
+	pages, err = LoadPages(context.Background(), fstest.MapFS{}, false, nil, 0)
+	a.NotError(err).Empty(pages)
+
+	// 与生成的文件冲突
+	fsys := fstest.MapFS{"pages/tags.md": &fstest.MapFile{Data: []byte("---\ntitle: tags\n---\n")}}
+	pages, err = LoadPages(context.Background(), fsys, false, nil, 0)
+	a.Error(err).Empty(pages)
+	fsys = fstest.MapFS{"pages/tags/index.md": &fstest.MapFile{Data: []byte("---\ntitle: tags\n---\n")}}
+	pages, err = LoadPages(context.Background(), fsys, false, nil, 0)
+	a.Error(err).Empty(pages)
+}
+
+func TestLoadPosts_bundle(t *testing.T) {
+	a := assert.New(t, false)
+	post := &fstest.MapFile{Data: []byte("---\ntitle: t\ntags: [api]\n---\n![img](img.png)\n")}
+
+	fsys := fstest.MapFS{
+		"posts/2024/p1/index.md": post,
+		"posts/2024/p1/img.png":  &fstest.MapFile{Data: []byte("png")},
+		"posts/2024/p2.md":       post,
+	}
+	posts, err := LoadPosts(context.Background(), fsys, false, nil, 0)
+	a.NotError(err).Length(posts, 2).
+		Equal(posts[0].Slug, "posts/2024/p1").True(posts[0].Bundle).
+		Equal(posts[1].Slug, "posts/2024/p2").False(posts[1].Bundle)
+
+	// slug 重复
+	fsys["posts/2024/p2/index.md"] = post
+	posts, err = LoadPosts(context.Background(), fsys, false, nil, 0)
+	a.Error(err).Empty(posts)
+
+	// 不能直接位于 posts 之下
+	fsys = fstest.MapFS{"posts/index.md": post}
+	posts, err = LoadPosts(context.Background(), fsys, false, nil, 0)
+	a.Error(err).Empty(posts)
+}
+
+func TestIsReservedPage(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.True(isReservedPage("index")).
+		True(isReservedPage("index-2")).
+		True(isReservedPage("tags")).
+		True(isReservedPage("archive")).
+		True(isReservedPage("posts/about")).
+		True(isReservedPage("tags/about")).
+		True(isReservedPage("series/about")).
+		False(isReservedPage("about")).
+		False(isReservedPage("index-about")).
+		False(isReservedPage("about/index"))
+}
+
 func TestLoadPost(t *testing.T) {
 	a := assert.New(t, false)
 
-	post, err := loadPost(testdata.Source, "posts/2020/12/p3.md")
+	post, err := loadPost(testdata.Source, vars.PostsDir, "posts/2020/12/p3.md", nil)
 	a.NotError(err).NotNil(post)
 	a.Equal(post.Title, "p3").Equal(post.Slug, "posts/2020/12/p3")
 
-	post, err = loadPost(testdata.Source, "posts/p1.md")
+	post, err = loadPost(testdata.Source, vars.PostsDir, "posts/p1.md", nil)
 	a.NotError(err).NotNil(post)
 	a.Equal(post.Title, "p1").Equal(post.Slug, "posts/p1").Equal(post.JSONLD, `{
     "@context": "https://schema.org/"
@@ -37,10 +103,60 @@ func TestLoadPost(t *testing.T) {
 `)
 }
 
+func TestPost_sanitize(t *testing.T) {
+	a := assert.New(t, false)
+	now := time.Now()
+
+	p := &Post{Title: "t", Tags: []string{"api"}, Created: now, Expires: now}
+	a.Error(p.sanitize(vars.PostsDir, "posts/p1.md"))
+
+	p = &Post{Title: "t", Tags: []string{"api"}, Created: now, Expires: now.Add(time.Hour)}
+	a.NotError(p.sanitize(vars.PostsDir, "posts/p1.md"))
+
+	p = &Post{Title: "t", Tags: []string{"api"}, State: StateUnlisted}
+	a.NotError(p.sanitize(vars.PostsDir, "posts/p1.md"))
+
+	p = &Post{Title: "t", Tags: []string{"api"}, State: "invalid"}
+	a.Error(p.sanitize(vars.PostsDir, "posts/p1.md"))
+
+	p = &Post{Title: "t", Tags: []string{"api"}, Aliases: []string{"/2020/old.html", "old/"}}
+	a.NotError(p.sanitize(vars.PostsDir, "posts/p1.md")).
+		Equal(p.Aliases, []string{"2020/old.html", "old/"})
+
+	p = &Post{Title: "t", Tags: []string{"api"}, Aliases: []string{"../old.html"}}
+	a.Error(p.sanitize(vars.PostsDir, "posts/p1.md"))
+
+	p = &Post{Title: "t", Tags: []string{"api"}, Aliases: []string{"/"}}
+	a.Error(p.sanitize(vars.PostsDir, "posts/p1.md"))
+}
+
+func TestSchedule(t *testing.T) {
+	a := assert.New(t, false)
+	now := time.Now()
+
+	p1 := &Post{Created: now.Add(-time.Hour)}
+	p2 := &Post{Created: now.Add(time.Hour)}                                    // 定时发布
+	p3 := &Post{Created: now.Add(-2 * time.Hour), Expires: now.Add(-time.Hour)} // 已过期
+	p4 := &Post{Created: now.Add(-2 * time.Hour), Expires: now.Add(30 * time.Minute)}
+
+	published, next := Schedule([]*Post{p1, p2, p3, p4}, now)
+	a.Equal(published, []*Post{p1, p4}).Equal(next, p4.Expires)
+
+	published, next = Schedule([]*Post{p1, p2, p3}, now)
+	a.Equal(published, []*Post{p1}).Equal(next, p2.Created)
+
+	published, next = Schedule([]*Post{p1}, now)
+	a.Equal(published, []*Post{p1}).Zero(next)
+}
+
 func TestSlug(t *testing.T) {
 	a := assert.New(t, false)
 
-	a.Equal(Slug("posts/p1.md"), "posts/p1.md")
+	a.Equal(Slug("posts/p1.md"), "posts/p1").
+		Equal(Slug("posts/p1.MD"), "posts/p1").
+		Equal(Slug("posts/2024/p1/index.md"), "posts/2024/p1").
+		Equal(Slug("index.md"), "index").
+		Equal(Slug("posts/img.png"), "posts/img.png")
 
 	a.Panic(func() {
 		Slug("posts/../p1.md")
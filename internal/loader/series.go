@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"strconv"
+
+	"github.com/issue9/localeutil"
+	"github.com/issue9/sliceutil"
+)
+
+// SeriesList 系列列表
+type SeriesList struct {
+	Series []*Series `yaml:"series,omitempty"`
+}
+
+// Series 描述系列信息
+type Series struct {
+	Title    string `yaml:"title"`
+	Content  string `yaml:"content"` // 对该系列的详细描述
+	Slug     string `yaml:"slug"`    // 唯一名称
+	Keywords string `yaml:"keywords,omitempty"`
+}
+
+// LoadSeries 加载系列列表
+//
+// 文件不存在时返回空值。
+func LoadSeries(f fs.FS, path string) (*SeriesList, error) {
+	series := &SeriesList{}
+	if err := loadYAML(f, path, &series); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := series.sanitize(); err != nil {
+		err.File = path
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (l *SeriesList) sanitize() *FieldError {
+	for index, s := range l.Series {
+		if err := s.sanitize(l); err != nil {
+			err.Field = "series[" + strconv.Itoa(index) + "]." + err.Field
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Series) sanitize(l *SeriesList) *FieldError {
+	if len(s.Slug) == 0 {
+		return &FieldError{Message: Required, Field: "slug"}
+	}
+
+	if len(s.Title) == 0 {
+		return &FieldError{Message: Required, Field: "title"}
+	}
+
+	if len(s.Content) == 0 {
+		return &FieldError{Message: Required, Field: "content"}
+	}
+
+	// 将 markdown 转换成 html
+	buf := new(bytes.Buffer)
+	if err := markdown.Convert([]byte(s.Content), buf); err != nil {
+		return &FieldError{Message: localeutil.Phrase(err.Error()), Field: "content", Value: s.Content}
+	}
+	s.Content = buf.String()
+
+	if l != nil {
+		cnt := sliceutil.Count(l.Series, func(i *Series, _ int) bool { return i.Slug == s.Slug })
+		if cnt > 1 {
+			return &FieldError{Message: DupValue, Field: "slug", Value: s.Slug}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/caixw/blogit/v2/internal/testdata"
+)
+
+func TestSeries_sanitize(t *testing.T) {
+	a := assert.New(t, false)
+	s := &Series{}
+	a.Error(s.sanitize(nil))
+
+	s.Slug = "s1"
+	a.Error(s.sanitize(nil))
+
+	s.Title = "t1"
+	a.Error(s.sanitize(nil))
+
+	s.Content = "c1"
+	a.NotError(s.sanitize(nil)).Equal(s.Content, "<p>c1</p>\n")
+	a.NotError(s.sanitize(&SeriesList{Series: []*Series{s}}))
+
+	p, err := newPrinter()
+	a.NotError(err).NotNil(p)
+	e := s.sanitize(&SeriesList{Series: []*Series{s, s}})
+	a.Contains(e.LocaleString(p), p.Sprintf("duplicate value"))
+}
+
+func TestLoadSeries(t *testing.T) {
+	a := assert.New(t, false)
+
+	series, err := LoadSeries(testdata.Source, "series.yaml")
+	a.NotError(err).NotNil(series).
+		Length(series.Series, 1).
+		Equal(series.Series[0].Slug, "example")
+
+	// 文件不存在
+	series, err = LoadSeries(testdata.Source, "not-exists.yaml")
+	a.NotError(err).Nil(series)
+
+	fsys := fstest.MapFS{"series.yaml": &fstest.MapFile{Data: []byte("series:\n- slug: s1\n")}}
+	series, err = LoadSeries(fsys, "series.yaml")
+	a.Error(err).Nil(series)
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 默认的阅读速度
+const (
+	DefaultReadingCJK   = 300 // 每分钟阅读的中日文字符数量
+	DefaultReadingWords = 200 // 每分钟阅读的其它语言单词数量
+)
+
+// Stats 文章的字数统计
+//
+// 由正文的纯文本统计而来，不包含代码块和 HTML。
+type Stats struct {
+	CJK   int // 中日文字符的数量，不包含标点。
+	Words int // 其它语言的单词数量
+	Chars int // 除空白之外的字符数量
+}
+
+// Reading 阅读速度的配置项
+type Reading struct {
+	CJK   int `yaml:"cjk,omitempty"`   // 每分钟阅读的中日文字符数量，默认为 300。
+	Words int `yaml:"words,omitempty"` // 每分钟阅读的其它语言单词数量，默认为 200。
+}
+
+func countText(text string) Stats {
+	var s Stats
+	inWord := false
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		s.Chars++
+
+		switch {
+		case isCJK(r):
+			inWord = false
+			if !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+				s.CJK++
+			}
+		case isWordRune(r):
+			if !inWord {
+				s.Words++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+	return s
+}
+
+func (r *Reading) sanitize() *FieldError {
+	if r.CJK < 0 {
+		return &FieldError{Message: GreatZero, Field: "cjk", Value: r.CJK}
+	} else if r.CJK == 0 {
+		r.CJK = DefaultReadingCJK
+	}
+
+	if r.Words < 0 {
+		return &FieldError{Message: GreatZero, Field: "words", Value: r.Words}
+	} else if r.Words == 0 {
+		r.Words = DefaultReadingWords
+	}
+
+	return nil
+}
+
+// Terms 将 text 切分成用于计算相似度的词
+//
+// 其它语言按单词切分并转换成小写，中日文没有明显的分隔符，采用相邻两个字符组成一个词，
+// 单独的一个字符则作为一个词。标点和空白均会被忽略。
+func Terms(text string) []string {
+	terms := make([]string, 0, len(text)/3)
+	word := make([]rune, 0, 20)
+	var cjk []rune
+
+	flush := func() {
+		if len(word) > 0 {
+			terms = append(terms, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+
+		switch len(cjk) {
+		case 0:
+		case 1:
+			terms = append(terms, string(cjk))
+		default:
+			for i := 0; i < len(cjk)-1; i++ {
+				terms = append(terms, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r) && !unicode.IsPunct(r) && !unicode.IsSymbol(r):
+			if len(word) > 0 {
+				flush()
+			}
+			cjk = append(cjk, r)
+		case isWordRune(r):
+			if len(cjk) > 0 {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return terms
+}
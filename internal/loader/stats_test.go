@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestCountText(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(countText(""), Stats{})
+	a.Equal(countText("hello world, it's 2024."), Stats{Words: 4, Chars: 20})
+	a.Equal(countText("中文，内容。"), Stats{CJK: 4, Chars: 6})
+	a.Equal(countText("使用go语言 and カタカナ"), Stats{CJK: 8, Words: 2, Chars: 13})
+
+	p, err := convert([]byte("---\ntitle: t\n---\n中文 text\n\n```go\nfunc main() {}\n```\n"))
+	a.NotError(err).Equal(p.Stats, Stats{CJK: 2, Words: 1, Chars: 6})
+
+	// HTML 实体不会被当作单词
+	p, err = convert([]byte("---\ntitle: t\n---\nTom &amp; Jerry &copy; 2024&nbsp;中文\n"))
+	a.NotError(err).Equal(p.Stats, Stats{CJK: 2, Words: 3, Chars: 16})
+}
+
+func TestReading_sanitize(t *testing.T) {
+	a := assert.New(t, false)
+
+	r := &Reading{}
+	a.NotError(r.sanitize()).
+		Equal(r.CJK, DefaultReadingCJK).
+		Equal(r.Words, DefaultReadingWords)
+
+	r = &Reading{CJK: 500, Words: 100}
+	a.NotError(r.sanitize()).Equal(r.CJK, 500).Equal(r.Words, 100)
+
+	r = &Reading{CJK: -1}
+	err := r.sanitize()
+	a.NotNil(err).Equal(err.Field, "cjk")
+}
+
+func TestTerms(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Empty(Terms("")).
+		Equal(Terms("Hello, World"), []string{"hello", "world"}).
+		Equal(Terms("中文分词。字"), []string{"中文", "文分", "分词", "字"}).
+		Equal(Terms("使用Go语言"), []string{"使用", "go", "语言"})
+
+	p, err := convert([]byte("---\ntitle: t\n---\nTom &amp; Jerry&nbsp;go\n"))
+	a.NotError(err).Equal(Terms(p.Text), []string{"tom", "jerry", "go"})
+}
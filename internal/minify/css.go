@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package minify
+
+import (
+	"bytes"
+	"strings"
+)
+
+// 这些字符前后的空白可以去掉
+//
+// 不包含 ( 和 :，比如 @media screen and (...) 和 div :first-child 中的空格都是有意义的。
+const (
+	cssSpaceBefore = "{};,>"
+	cssSpaceAfter  = "{};,>:"
+)
+
+// CSS 压缩 CSS 内容
+//
+// 去掉注释（以 /*! 开头的除外）以及多余的空白字符，以及 } 之前的最后一个分号。
+func CSS(data []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.Grow(len(data))
+
+	space := false // 是否有待输出的空白
+	for i := 0; i < len(data); {
+		c := data[i]
+
+		switch {
+		case isSpace(c):
+			space = true
+			i++
+			continue
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(data)
+			} else {
+				end += i + 4
+			}
+			if i+2 < len(data) && data[i+2] == '!' {
+				writeCSSSpace(buf, space, '/')
+				buf.Write(data[i:end])
+				space = false
+			}
+			i = end
+			continue
+		}
+
+		writeCSSSpace(buf, space, c)
+		space = false
+
+		switch c {
+		case '"', '\'':
+			end := skipString(data, i, c)
+			buf.Write(data[i:end])
+			i = end
+			continue
+		case '}':
+			if b := buf.Bytes(); len(b) > 0 && b[len(b)-1] == ';' {
+				buf.Truncate(len(b) - 1)
+			}
+		}
+
+		buf.WriteByte(c)
+		i++
+	}
+
+	return buf.Bytes()
+}
+
+// 根据前后的字符决定是否需要输出空白
+func writeCSSSpace(buf *bytes.Buffer, space bool, next byte) {
+	if !space || buf.Len() == 0 {
+		return
+	}
+
+	prev := buf.Bytes()[buf.Len()-1]
+	if strings.IndexByte(cssSpaceAfter, prev) >= 0 || strings.IndexByte(cssSpaceBefore, next) >= 0 {
+		return
+	}
+	buf.WriteByte(' ')
+}
@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package minify
+
+import (
+	"bytes"
+	"strings"
+)
+
+// 块级元素，其前后的空白不会影响显示效果。
+var htmlBlockTags = map[string]struct{}{
+	"!doctype": {}, "html": {}, "head": {}, "body": {}, "title": {}, "meta": {}, "link": {}, "base": {},
+	"script": {}, "style": {}, "noscript": {}, "template": {},
+	"div": {}, "p": {}, "ul": {}, "ol": {}, "li": {}, "dl": {}, "dt": {}, "dd": {},
+	"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {}, "hr": {}, "br": {},
+	"header": {}, "footer": {}, "nav": {}, "section": {}, "article": {}, "aside": {}, "main": {},
+	"table": {}, "thead": {}, "tbody": {}, "tfoot": {}, "tr": {}, "td": {}, "th": {}, "caption": {},
+	"colgroup": {}, "col": {}, "form": {}, "fieldset": {}, "legend": {}, "figure": {}, "figcaption": {},
+	"blockquote": {}, "pre": {}, "address": {}, "details": {}, "summary": {},
+	"option": {}, "optgroup": {},
+}
+
+// 内容需要原样输出的元素
+var htmlRawTags = map[string]struct{}{
+	"pre": {}, "textarea": {}, "script": {}, "style": {},
+}
+
+// HTML 压缩 HTML 内容
+//
+// 去掉注释（条件注释除外）以及多余的空白字符，块级元素之间的空白会被完全去掉。
+// pre 和 textarea 中的内容原样输出，style 和 script 中的内容则分别作为 CSS 和 JS 进行压缩。
+func HTML(data []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.Grow(len(data))
+
+	var prevTag string // 上一个标签的名称
+	for i := 0; i < len(data); {
+		if data[i] != '<' {
+			end := bytes.IndexByte(data[i:], '<')
+			if end < 0 {
+				end = len(data)
+			} else {
+				end += i
+			}
+			writeHTMLText(buf, data[i:end], prevTag, htmlTagName(data[end:]), end == len(data))
+			i = end
+			continue
+		}
+
+		// 注释
+		if bytes.HasPrefix(data[i:], []byte("<!--")) {
+			end := bytes.Index(data[i+4:], []byte("-->"))
+			if end < 0 {
+				end = len(data)
+			} else {
+				end += i + 7
+			}
+			if bytes.HasPrefix(data[i+4:], []byte("[if")) {
+				buf.Write(data[i:end])
+			}
+			i = end
+			continue
+		}
+
+		name := htmlTagName(data[i:])
+		if name == "" { // 不是标签，作为普通字符处理
+			buf.WriteByte('<')
+			prevTag = ""
+			i++
+			continue
+		}
+
+		before := buf.Len()
+		end := writeHTMLTag(buf, data, i)
+		open := string(buf.Bytes()[before:])
+		prevTag = name
+		i = end
+
+		if _, found := htmlRawTags[name]; !found || name[0] == '/' {
+			continue
+		}
+
+		// 查找结束标签
+		closeTag := []byte("</" + name)
+		rawEnd := bytes.Index(bytes.ToLower(data[i:]), closeTag)
+		if rawEnd < 0 {
+			rawEnd = len(data)
+		} else {
+			rawEnd += i
+		}
+
+		raw := data[i:rawEnd]
+		switch name {
+		case "style":
+			raw = CSS(raw)
+		case "script":
+			switch typ := htmlScriptType(open); {
+			case typ == "" || strings.Contains(typ, "javascript") || typ == "module":
+				raw = JS(raw)
+			case strings.Contains(typ, "json"):
+				raw = JSON(bytes.TrimSpace(raw))
+			}
+		}
+		buf.Write(raw)
+		i = rawEnd
+	}
+
+	return buf.Bytes()
+}
+
+// 返回以 < 开头的标签名称，结束标签会带上 /，且都转换为小写。
+//
+// 如果不是一个标签，返回空值。
+func htmlTagName(data []byte) string {
+	if len(data) < 2 || data[0] != '<' {
+		return ""
+	}
+
+	i := 1
+	if data[i] == '/' || data[i] == '!' {
+		i++
+	}
+	start := i
+	for ; i < len(data); i++ {
+		c := data[i]
+		if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') && c != '-' {
+			break
+		}
+	}
+	if i == start {
+		return ""
+	}
+
+	return strings.ToLower(string(data[1:i]))
+}
+
+// 输出从 start 开始的标签并返回标签结束之后的位置
+//
+// 属性之间的空白会被合并为一个空格，属性值原样输出。
+func writeHTMLTag(buf *bytes.Buffer, data []byte, start int) int {
+	space := false
+	for i := start; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case isSpace(c):
+			space = true
+			continue
+		case c == '"' || c == '\'':
+			end := skipQuoted(data, i, c)
+			if space {
+				buf.WriteByte(' ')
+				space = false
+			}
+			buf.Write(data[i:end])
+			i = end - 1
+			continue
+		case c == '>':
+			buf.WriteByte(c)
+			return i + 1
+		}
+
+		if space && c != '/' && c != '=' && buf.Bytes()[buf.Len()-1] != '=' {
+			buf.WriteByte(' ')
+		}
+		space = false
+		buf.WriteByte(c)
+	}
+	return len(data)
+}
+
+// 与 skipString 相同，但是 HTML 的属性值不支持转义。
+func skipQuoted(data []byte, start int, quote byte) int {
+	end := bytes.IndexByte(data[start+1:], quote)
+	if end < 0 {
+		return len(data)
+	}
+	return start + end + 2
+}
+
+// 输出标签之间的文本
+//
+// prev 和 next 分别为文本前后的标签名称，eof 表示文本之后是否已经没有内容。
+func writeHTMLText(buf *bytes.Buffer, text []byte, prev, next string, eof bool) {
+	// 合并空白字符
+	out := make([]byte, 0, len(text))
+	space := false
+	for _, c := range text {
+		if isSpace(c) {
+			space = true
+			continue
+		}
+		if space {
+			out = append(out, ' ')
+			space = false
+		}
+		out = append(out, c)
+	}
+	if space {
+		out = append(out, ' ')
+	}
+
+	if b := buf.Bytes(); isHTMLBlockTag(prev) || len(b) == 0 || b[len(b)-1] == ' ' {
+		out = bytes.TrimLeft(out, " ")
+	}
+	if isHTMLBlockTag(next) || eof {
+		out = bytes.TrimRight(out, " ")
+	}
+	buf.Write(out)
+}
+
+func isHTMLBlockTag(name string) bool {
+	_, found := htmlBlockTags[strings.TrimPrefix(name, "/")]
+	return found
+}
+
+// 获取 script 标签的 type 属性值
+func htmlScriptType(tag string) string {
+	tag = strings.ToLower(tag)
+	index := strings.Index(tag, " type=")
+	if index < 0 {
+		return ""
+	}
+
+	v := tag[index+6:]
+	if len(v) > 0 && (v[0] == '"' || v[0] == '\'') {
+		if end := strings.IndexByte(v[1:], v[0]); end >= 0 {
+			return v[1 : end+1]
+		}
+	}
+	if end := strings.IndexAny(v, " >"); end >= 0 {
+		return v[:end]
+	}
+	return v
+}
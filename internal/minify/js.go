@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package minify
+
+import (
+	"bytes"
+	"strings"
+)
+
+// 这些字符前后的空格可以去掉
+const jsSpace = "{}()[];,=:?"
+
+// 在这些字符之后出现的 / 表示正则表达式的开始
+const jsRegexpPrev = "(,=:[!&|?{};+-*%<>~^"
+
+// 在这些关键字之后出现的 / 表示正则表达式的开始
+var jsRegexpKeywords = []string{
+	"return", "typeof", "case", "do", "else", "in", "of", "new",
+	"delete", "void", "throw", "instanceof", "yield", "await",
+}
+
+// JS 压缩 JS 内容
+//
+// 去掉注释（以 /*! 开头的除外）以及多余的空白字符。
+// 为了不影响自动插入分号的规则，大部分换行符都会被保留。
+func JS(data []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.Grow(len(data))
+
+	var space, newline bool // 是否有待输出的空格和换行符
+	for i := 0; i < len(data); {
+		c := data[i]
+
+		switch {
+		case c == '\n' || c == '\r':
+			newline = true
+			i++
+			continue
+		case isSpace(c):
+			space = true
+			i++
+			continue
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			end := bytes.IndexByte(data[i:], '\n')
+			if end < 0 {
+				end = len(data)
+			} else {
+				end += i
+			}
+			i = end
+			continue
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(data)
+			} else {
+				end += i + 4
+			}
+			if i+2 < len(data) && data[i+2] == '!' {
+				writeJSSpace(buf, space, newline, '/')
+				buf.Write(data[i:end])
+				space, newline = false, false
+			} else if bytes.IndexByte(data[i:end], '\n') >= 0 {
+				newline = true // 包含换行符的注释相当于一个换行符
+			} else {
+				space = true
+			}
+			i = end
+			continue
+		}
+
+		regexp := c == '/' && isJSRegexpStart(buf.Bytes())
+		writeJSSpace(buf, space, newline, c)
+		space, newline = false, false
+
+		var end int
+		switch {
+		case c == '"' || c == '\'':
+			end = skipString(data, i, c)
+		case c == '`':
+			end = skipTemplate(data, i)
+		case regexp:
+			end = skipRegexp(data, i)
+		default:
+			buf.WriteByte(c)
+			i++
+			continue
+		}
+		buf.Write(data[i:end])
+		i = end
+	}
+
+	return buf.Bytes()
+}
+
+// 根据前后的字符决定是否需要输出空白
+func writeJSSpace(buf *bytes.Buffer, space, newline bool, next byte) {
+	if (!space && !newline) || buf.Len() == 0 {
+		return
+	}
+	prev := buf.Bytes()[buf.Len()-1]
+
+	if newline {
+		if strings.IndexByte("{;,", prev) < 0 && next != '}' {
+			buf.WriteByte('\n')
+		}
+		return
+	}
+
+	if strings.IndexByte(jsSpace, prev) < 0 && strings.IndexByte(jsSpace, next) < 0 {
+		buf.WriteByte(' ')
+	}
+}
+
+// 根据已经输出的内容判断 / 是否为正则表达式的开始
+func isJSRegexpStart(out []byte) bool {
+	out = bytes.TrimRight(out, " \n")
+	if len(out) == 0 {
+		return true
+	}
+
+	prev := out[len(out)-1]
+	if strings.IndexByte(jsRegexpPrev, prev) >= 0 {
+		return true
+	}
+
+	for _, k := range jsRegexpKeywords {
+		if bytes.HasSuffix(out, []byte(k)) {
+			if len(out) == len(k) || !isJSIdent(out[len(out)-len(k)-1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isJSIdent(b byte) bool {
+	return b == '_' || b == '$' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// 跳过模板字符串，返回模板字符串结束之后的位置。
+func skipTemplate(data []byte, start int) int {
+	depth := 0 // ${} 的嵌套深度
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '$':
+			if i+1 < len(data) && data[i+1] == '{' {
+				depth++
+				i++
+			}
+		case '{':
+			if depth > 0 {
+				depth++
+			}
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case '`':
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(data)
+}
+
+// 跳过正则表达式，返回正则表达式结束之后的位置，不包含修饰符。
+func skipRegexp(data []byte, start int) int {
+	class := false // 是否在 [] 中
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '[':
+			class = true
+		case ']':
+			class = false
+		case '/':
+			if !class {
+				return i + 1
+			}
+		case '\n':
+			return i // 不是合法的正则表达式
+		}
+	}
+	return len(data)
+}
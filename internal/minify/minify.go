@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package minify 提供对 HTML、CSS、JS 和 JSON 的压缩
+//
+// 所有的压缩都是保守的，只去掉注释和不影响结果的空白字符，不会对内容进行改写。
+// 对于无法正确解析的内容，会尽量原样输出。
+package minify
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// JSON 压缩 JSON 内容
+//
+// 如果不是合法的 JSON，则原样返回。
+func JSON(data []byte) []byte {
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+// 从 data[start] 开始，跳过以 quote 结尾的字符串，返回字符串结束之后的位置。
+//
+// data[start] 应该为字符串的开始字符，支持 \ 转义。
+func skipString(data []byte, start int, quote byte) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(data)
+}
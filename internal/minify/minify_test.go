@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package minify
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestJSON(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(string(JSON([]byte("{\n\t\"a\": [1, 2],\n\t\"b\": \"x y\"\n}\n"))), `{"a":[1,2],"b":"x y"}`)
+	a.Equal(string(JSON([]byte("{\"a\": "))), "{\"a\": ") // 无效的内容
+}
+
+func TestCSS(t *testing.T) {
+	a := assert.New(t, false)
+
+	data := []byte(`
+/* comment */
+/*! license */
+body , p > a {
+	color : red ; /* : 之前的空格无法判断是否为选择器的一部分，所以保留 */
+	font-family: "a  b", sans-serif;
+}
+
+@media screen and (max-width: 100px) {
+	div :first-child { margin: 0 auto; }
+}
+a::after { content: '{ ; }' }
+`)
+	a.Equal(string(CSS(data)), `/*! license */ body,p>a{color :red;font-family:"a  b",sans-serif}@media screen and (max-width:100px){div :first-child{margin:0 auto}}a::after{content:'{ ; }'}`)
+
+	a.Equal(string(CSS([]byte("a{} /* 未结束的注释"))), "a{}")
+}
+
+func TestJS(t *testing.T) {
+	a := assert.New(t, false)
+
+	data := []byte(`
+// comment
+/*! license */
+function f ( a , b ) {
+	/* block */
+	const s = "a // b /* c */";
+	const t = ` + "`x ${ a + `y` } // z`" + `;
+	const r = /[/]\/\/x/g.test(s);
+	return a / b
+}
+
+let x = 1
+let y = x
+++x
+`)
+	a.Equal(string(JS(data)), "/*! license */\nfunction f(a,b){const s=\"a // b /* c */\";const t=`x ${ a + `y` } // z`;const r=/[/]\\/\\/x/g.test(s);return a / b}\nlet x=1\nlet y=x\n++x")
+}
+
+func TestHTML(t *testing.T) {
+	a := assert.New(t, false)
+
+	data := []byte(`<!DOCTYPE html>
+<html>
+    <head>
+        <title>  title  </title>
+        <!-- comment -->
+        <!--[if IE]><p>ie</p><![endif]-->
+        <style>
+            body { color : red; }
+        </style>
+        <script type="application/ld+json">
+            { "a" : 1 }
+        </script>
+        <script>
+            // comment
+            let a = 1 < 2
+        </script>
+    </head>
+    <body   class="a  b" >
+        <p>
+            <b>a</b> <i>b</i>
+            1 < 2
+        </p>
+        <pre>
+  x   y
+</pre>
+        <textarea>  a  </textarea>
+        <br />
+    </body>
+</html>
+`)
+	a.Equal(string(HTML(data)), `<!DOCTYPE html><html><head><title>title</title><!--[if IE]><p>ie</p><![endif]--><style>body{color :red}</style><script type="application/ld+json">{"a":1}</script><script>let a=1 < 2</script></head><body class="a  b"><p><b>a</b> <i>b</i> 1 < 2</p><pre>
+  x   y
+</pre><textarea>  a  </textarea><br/></body></html>`)
+}
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package pool 提供并发执行任务的功能
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// Run 以最多 n 个 goroutine 执行 f(0) 至 f(size-1)
+//
+// 在开始每一个任务之前都会检测 ctx 是否已经取消，如果已经取消，
+// 则不再开始新的任务，并返回 ctx.Err()。
+//
+// 任意一个任务返回错误之后，不会再开始新的任务，
+// 在等待已经开始的任务完成之后返回出错任务中索引值最小的那个错误，
+// 以保证与按顺序执行时的行为尽量一致。
+//
+// n 小于等于 1 时，所有的任务按顺序在当前 goroutine 中执行。
+func Run(ctx context.Context, n, size int, f func(i int) error) error {
+	if n <= 1 || size <= 1 {
+		for i := 0; i < size; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := f(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if n > size {
+		n = size
+	}
+
+	var (
+		mux    sync.Mutex
+		next   int
+		errIdx = -1
+		err    error
+		wg     sync.WaitGroup
+	)
+
+	// 获取下一个需要执行的任务，返回 -1 表示没有任务了。
+	take := func() int {
+		mux.Lock()
+		defer mux.Unlock()
+
+		if err != nil || next >= size {
+			return -1
+		}
+		i := next
+		next++
+		return i
+	}
+
+	fail := func(i int, e error) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		if errIdx == -1 || i < errIdx {
+			errIdx = i
+			err = e
+		}
+	}
+
+	wg.Add(n)
+	for j := 0; j < n; j++ {
+		go func() {
+			defer wg.Done()
+			for i := take(); i >= 0; i = take() {
+				if e := ctx.Err(); e != nil {
+					fail(i, e)
+					continue
+				}
+				if e := f(i); e != nil {
+					fail(i, e)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return err
+}
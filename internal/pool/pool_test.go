@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2020-2024 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestRun(t *testing.T) {
+	a := assert.New(t, false)
+
+	for _, n := range []int{0, 1, 4, 100} {
+		result := make([]int, 50)
+		a.NotError(Run(context.Background(), n, len(result), func(i int) error {
+			result[i] = i * 2
+			return nil
+		}))
+		for i, v := range result {
+			a.Equal(v, i*2)
+		}
+	}
+
+	a.NotError(Run(context.Background(), 4, 0, func(int) error { panic("不应该执行") }))
+
+	// 出错之后不再执行新的任务
+
+	err1 := errors.New("err1")
+	err2 := errors.New("err2")
+	var count int32
+	err := Run(context.Background(), 4, 1000, func(i int) error {
+		atomic.AddInt32(&count, 1)
+		switch i {
+		case 5:
+			return err2
+		case 3:
+			return err1
+		}
+		return nil
+	})
+	a.Equal(err, err1).True(atomic.LoadInt32(&count) < 1000)
+
+	count = 0
+	err = Run(context.Background(), 1, 1000, func(i int) error {
+		count++
+		if i == 5 {
+			return err2
+		}
+		return nil
+	})
+	a.Equal(err, err2).Equal(count, 6)
+
+	// 取消
+
+	for _, n := range []int{1, 4} {
+		ctx, cancel := context.WithCancel(context.Background())
+		count = 0
+		err = Run(ctx, n, 1000, func(i int) error {
+			if atomic.AddInt32(&count, 1) == 10 {
+				cancel()
+			}
+			return nil
+		})
+		a.ErrorIs(err, context.Canceled).True(atomic.LoadInt32(&count) < 1000)
+	}
+}
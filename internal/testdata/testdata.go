@@ -6,13 +6,38 @@ package testdata
 
 import (
 	"embed"
+	"io/fs"
 	"os"
+	"testing"
+	"testing/fstest"
 )
 
-//go:embed posts themes conf.yaml tags.yaml
+//go:embed posts pages themes conf.yaml tags.yaml series.yaml
 var Source embed.FS
 
 // Temp 创建一个临时的文件夹
 func Temp() (string, error) {
 	return os.MkdirTemp(os.TempDir(), "blogit")
 }
+
+// MapFS 将 [Source] 复制为可修改的 fstest.MapFS
+func MapFS(tb testing.TB) fstest.MapFS {
+	tb.Helper()
+
+	fsys := fstest.MapFS{}
+	err := fs.WalkDir(Source, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(Source, p)
+		if err == nil {
+			fsys[p] = &fstest.MapFile{Data: data}
+		}
+		return err
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return fsys
+}
@@ -10,13 +10,16 @@ const (
 	Name = "blogit"
 	URL  = "https://github.com/caixw/blogit"
 
-	ConfYAML  = "conf.yaml"
-	TagsYAML  = "tags.yaml"
-	ThemeYAML = "theme.yaml"
+	ConfYAML   = "conf.yaml"
+	TagsYAML   = "tags.yaml"
+	SeriesYAML = "series.yaml"
+	ThemeYAML  = "theme.yaml"
 
 	ThemesDir = "themes"
 	PostsDir  = "posts"
+	PagesDir  = "pages" // 独立页面，不会出现在索引、订阅、存档和标签中。
 	TagsDir   = "tags"
+	SeriesDir = "series"
 	LayoutDir = "layout"
 
 	TagsFilename        = "tags" + Ext
@@ -26,16 +29,23 @@ const (
 	RssXML              = "rss.xml"
 	AtomXML             = "atom.xml"
 	SitemapXML          = "sitemap.xml"
+	RedirectsFilename   = "_redirects"                  // Netlify 等平台使用的重定向规则
+	RedirectsMap        = "redirects.map"               // nginx 的 map 指令可使用的重定向规则
+	ManifestJSON        = "." + Name + "-manifest.json" // 编译的清单文件
+	DirFSRecordJSON     = "." + Name + "-files.json"    // DirFS 写入文件的记录，以输出目录名作为前缀
 
 	DefaultTemplate = "post"
+	PageTemplate    = "page" // 独立页面的默认模板
 	IndexTemplate   = "index"
 	TagTemplate     = "tag"
 	TagsTemplate    = "tags"
+	SeriesTemplate  = "series" // 系列的概览页面
 	ArchiveTemplate = "archive"
 
 	Ext              = ".html" // 生成后的文件后缀名
 	MarkdownExt      = ".md"
-	DraftTitleAround = "**" // 草稿文章的标题围绕的字符
+	BundleIndex      = "index" // 页面包中文章的文件名，不包含后缀名。
+	DraftTitleAround = "**"    // 草稿文章的标题围绕的字符
 
 	HighlightClassPrefix = "hl-" // 语法高亮的统一类名前缀
 